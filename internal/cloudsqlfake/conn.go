@@ -0,0 +1,544 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudsqlfake
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ driver.Conn           = &conn{}
+	_ driver.ExecerContext  = &conn{}
+	_ driver.QueryerContext = &conn{}
+	_ driver.Stmt           = &stmt{}
+	_ driver.Rows           = &rows{}
+)
+
+var (
+	callStatement       = regexp.MustCompile(`^CALL mysql\.(\w+)\((.*)\)$`)
+	selectVariables     = regexp.MustCompile(`^SELECT (@\w+(?:, @\w+)*)$`)
+	showGlobalVariables = regexp.MustCompile(`^SHOW GLOBAL VARIABLES LIKE '([^']*)'$`)
+)
+
+const createMetadataTablePrefix = "CREATE TABLE IF NOT EXISTS tf_audit_rule_metadata "
+
+// statements maps the normalized form of the supported statements to their
+// implementation, anything else is rejected so that new queries in the
+// provider are noticed by the tests.
+var statements = map[string]func(c *conn, args []driver.Value) (*result, error){
+	"SELECT id, username, dbname, object, operation, op_result FROM audit_log_rules ORDER BY id": func(c *conn, _ []driver.Value) (*result, error) {
+		return c.server.selectRules(func(Rule) bool { return true }), nil
+	},
+	"SELECT id, username, dbname, object, operation, op_result FROM audit_log_rules WHERE id = ?": func(c *conn, args []driver.Value) (*result, error) {
+		id, _ := intArg(args[0])
+		return c.server.selectRules(func(r Rule) bool { return r.ID == id }), nil
+	},
+	"SELECT id FROM audit_log_rules WHERE username = ? AND dbname = ? AND object = ? AND operation = ? AND op_result = ?": func(c *conn, args []driver.Value) (*result, error) {
+		res := c.server.selectRules(func(r Rule) bool {
+			return r.Username == args[0] && r.Dbname == args[1] && r.Object == args[2] && r.Operation == args[3] && r.OpResult == args[4]
+		})
+		for i, row := range res.values {
+			res.values[i] = row[:1]
+		}
+		res.columns = res.columns[:1]
+
+		return res, nil
+	},
+	"SELECT rule_id, description, owner, ticket FROM tf_audit_rule_metadata": func(c *conn, _ []driver.Value) (*result, error) {
+		return c.server.selectMetadata(func(Metadata) bool { return true })
+	},
+	"SELECT rule_id, description, owner, ticket FROM tf_audit_rule_metadata WHERE rule_id = ?": func(c *conn, args []driver.Value) (*result, error) {
+		id, _ := intArg(args[0])
+		return c.server.selectMetadata(func(m Metadata) bool { return m.RuleID == id })
+	},
+	"SELECT COUNT(*) FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'tf_audit_rule_metadata'": func(c *conn, _ []driver.Value) (*result, error) {
+		var tables int64
+		if c.server.MetadataTableExists() {
+			tables = 1
+		}
+
+		return &result{columns: []string{"COUNT(*)"}, values: [][]driver.Value{{tables}}}, nil
+	},
+	"INSERT INTO tf_audit_rule_metadata (rule_id, description, owner, ticket) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE description = VALUES(description), owner = VALUES(owner), ticket = VALUES(ticket)": func(c *conn, args []driver.Value) (*result, error) {
+		return c.server.upsertMetadata(args)
+	},
+	"DELETE FROM tf_audit_rule_metadata WHERE rule_id = ?": func(c *conn, args []driver.Value) (*result, error) {
+		return c.server.deleteMetadata(args[0])
+	},
+	"SELECT User, Host FROM mysql.user ORDER BY User, Host": func(c *conn, _ []driver.Value) (*result, error) {
+		return c.server.selectAccounts(), nil
+	},
+	"SELECT SCHEMA_NAME FROM information_schema.SCHEMATA": func(c *conn, _ []driver.Value) (*result, error) {
+		return c.server.selectSchemas(), nil
+	},
+	"SELECT TABLE_SCHEMA, TABLE_NAME FROM information_schema.TABLES UNION ALL SELECT ROUTINE_SCHEMA, ROUTINE_NAME FROM information_schema.ROUTINES": func(c *conn, _ []driver.Value) (*result, error) {
+		return c.server.selectSchemaObjects(), nil
+	},
+	"SELECT PLUGIN_STATUS, PLUGIN_VERSION FROM information_schema.PLUGINS WHERE PLUGIN_NAME = ?": func(c *conn, args []driver.Value) (*result, error) {
+		return c.server.selectPlugin(args[0]), nil
+	},
+	"SELECT VARIABLE_VALUE FROM performance_schema.global_status WHERE VARIABLE_NAME = 'Uptime'": func(c *conn, _ []driver.Value) (*result, error) {
+		return &result{
+			columns: []string{"VARIABLE_VALUE"},
+			values:  [][]driver.Value{{strconv.FormatInt(c.server.Uptime, 10)}},
+		}, nil
+	},
+	"SELECT USER, HOST, EVENT_NAME, COUNT_STAR, SUM_ERRORS FROM performance_schema.events_statements_summary_by_account_by_event_name WHERE USER IS NOT NULL AND COUNT_STAR > 0": func(c *conn, _ []driver.Value) (*result, error) {
+		return c.server.selectStatementSummary(), nil
+	},
+	"SELECT db, query, exec_count FROM sys.x$statement_analysis": func(c *conn, _ []driver.Value) (*result, error) {
+		return c.server.selectStatementAnalysis()
+	},
+}
+
+// result is the outcome of a statement, the rows are only used by queries.
+type result struct {
+	columns      []string
+	values       [][]driver.Value
+	rowsAffected int64
+}
+
+func (r *result) LastInsertId() (int64, error) {
+	return 0, errors.New("cloudsqlfake: LastInsertId is not supported")
+}
+
+func (r *result) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+// conn is a session on the fake instance.
+type conn struct {
+	server *Server
+	vars   map[string]driver.Value
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return nil, errors.New("cloudsqlfake: transactions are not supported")
+}
+
+func (c *conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.run(query, namedValues(args))
+}
+
+func (c *conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	res, err := c.run(query, namedValues(args))
+	if err != nil {
+		return nil, err
+	}
+
+	return &rows{result: res}, nil
+}
+
+func (c *conn) run(query string, args []driver.Value) (*result, error) {
+	query = normalize(query)
+
+	if run, ok := statements[query]; ok {
+		if want := strings.Count(query, "?"); want != len(args) {
+			return nil, fmt.Errorf("cloudsqlfake: expected %d arguments, got %d", want, len(args))
+		}
+
+		return run(c, args)
+	}
+
+	if m := callStatement.FindStringSubmatch(query); m != nil {
+		return c.call(m[1], m[2], args)
+	}
+
+	if m := selectVariables.FindStringSubmatch(query); m != nil {
+		res := &result{values: [][]driver.Value{{}}}
+		for _, name := range strings.Split(m[1], ", ") {
+			res.columns = append(res.columns, name)
+			res.values[0] = append(res.values[0], c.vars[name])
+		}
+
+		return res, nil
+	}
+
+	if m := showGlobalVariables.FindStringSubmatch(query); m != nil {
+		return c.server.showVariables(m[1]), nil
+	}
+
+	if strings.HasPrefix(query, createMetadataTablePrefix) {
+		return c.server.createMetadataTable()
+	}
+
+	return nil, fmt.Errorf("cloudsqlfake: unsupported statement: %s", query)
+}
+
+// call runs a procedure, the arguments may be placeholders, integer literals
+// or session variables which are used for the out parameters.
+func (c *conn) call(name, argList string, args []driver.Value) (*result, error) {
+	want, ok := procedures[name]
+	if !ok {
+		return nil, &mysql.MySQLError{
+			Number:  1305,
+			Message: fmt.Sprintf("PROCEDURE mysql.%s does not exist", name),
+		}
+	}
+
+	params := strings.Split(argList, ", ")
+	if len(params) != want {
+		return nil, &mysql.MySQLError{
+			Number:  1318,
+			Message: fmt.Sprintf("Incorrect number of arguments for PROCEDURE mysql.%s; expected %d, got %d", name, want, len(params)),
+		}
+	}
+
+	in := make([]driver.Value, 0, want-2)
+	for _, param := range params[:want-2] {
+		switch {
+		case param == "?":
+			if len(args) == 0 {
+				return nil, errors.New("cloudsqlfake: not enough arguments")
+			}
+			in = append(in, args[0])
+			args = args[1:]
+		case param == "NULL":
+			in = append(in, nil)
+		case strings.HasPrefix(param, "'") && strings.HasSuffix(param, "'"):
+			in = append(in, strings.Trim(param, "'"))
+		default:
+			v, err := strconv.ParseInt(param, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("cloudsqlfake: unsupported procedure argument: %s", param)
+			}
+			in = append(in, v)
+		}
+	}
+
+	outval, outmsg := params[want-2], params[want-1]
+	if !strings.HasPrefix(outval, "@") || !strings.HasPrefix(outmsg, "@") {
+		return nil, &mysql.MySQLError{
+			Number:  1414,
+			Message: fmt.Sprintf("OUT or INOUT argument for routine mysql.%s is not a variable", name),
+		}
+	}
+
+	res := c.server.call(name, in)
+	c.vars[outval] = res.outval
+	c.vars[outmsg] = res.outmsg
+
+	return &result{}, nil
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.run(s.query, args)
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	res, err := s.conn.run(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rows{result: res}, nil
+}
+
+type rows struct {
+	result *result
+	next   int
+}
+
+func (r *rows) Columns() []string {
+	return r.result.columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.result.values) {
+		return io.EOF
+	}
+
+	copy(dest, r.result.values[r.next])
+	r.next++
+
+	return nil
+}
+
+// normalize drops the sqlc name comments, backticks, trailing semicolons and
+// extra whitespace so that statements can be compared.
+func normalize(query string) string {
+	var lines []string
+	for _, line := range strings.Split(query, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	query = strings.Join(strings.Fields(strings.Join(lines, " ")), " ")
+	query = strings.ReplaceAll(query, "`", "")
+
+	return strings.TrimSuffix(query, ";")
+}
+
+func namedValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	return values
+}
+
+func (s *Server) selectRules(match func(Rule) bool) *result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := &result{columns: []string{"id", "username", "dbname", "object", "operation", "op_result"}}
+	for _, rule := range s.sortedRules() {
+		if match(rule) {
+			res.values = append(res.values, []driver.Value{rule.ID, rule.Username, rule.Dbname, rule.Object, rule.Operation, rule.OpResult})
+		}
+	}
+
+	return res
+}
+
+func errNoMetadataTable() error {
+	return &mysql.MySQLError{
+		Number:  1146,
+		Message: "Table 'mysql.tf_audit_rule_metadata' doesn't exist",
+	}
+}
+
+func (s *Server) createMetadataTable() (*result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.DenyCreateTable {
+		return nil, &mysql.MySQLError{
+			Number:  1142,
+			Message: "CREATE command denied to user for table 'tf_audit_rule_metadata'",
+		}
+	}
+
+	s.metadataTable = true
+
+	return &result{}, nil
+}
+
+func (s *Server) selectMetadata(match func(Metadata) bool) (*result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.metadataTable {
+		return nil, errNoMetadataTable()
+	}
+
+	ids := make([]int64, 0, len(s.metadata))
+	for id := range s.metadata {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	res := &result{columns: []string{"rule_id", "description", "owner", "ticket"}}
+	for _, id := range ids {
+		m := s.metadata[id]
+		if match(m) {
+			res.values = append(res.values, []driver.Value{m.RuleID, nullable(m.Description), nullable(m.Owner), nullable(m.Ticket)})
+		}
+	}
+
+	return res, nil
+}
+
+func (s *Server) upsertMetadata(args []driver.Value) (*result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.metadataTable {
+		return nil, errNoMetadataTable()
+	}
+
+	id, ok := intArg(args[0])
+	if !ok {
+		return nil, fmt.Errorf("cloudsqlfake: invalid rule_id %v", args[0])
+	}
+
+	m := Metadata{RuleID: id}
+	for i, field := range []**string{&m.Description, &m.Owner, &m.Ticket} {
+		if v, ok := args[i+1].(string); ok {
+			*field = &v
+		}
+	}
+
+	// like mysql, an update counts as two affected rows
+	res := &result{rowsAffected: 1}
+	if _, ok := s.metadata[id]; ok {
+		res.rowsAffected = 2
+	}
+	s.metadata[id] = m
+
+	return res, nil
+}
+
+func (s *Server) deleteMetadata(id driver.Value) (*result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.metadataTable {
+		return nil, errNoMetadataTable()
+	}
+
+	res := &result{}
+	if ruleID, ok := intArg(id); ok {
+		if _, ok := s.metadata[ruleID]; ok {
+			delete(s.metadata, ruleID)
+			res.rowsAffected = 1
+		}
+	}
+
+	return res, nil
+}
+
+func (s *Server) selectAccounts() *result {
+	accounts := append([]Account(nil), s.Accounts...)
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].User != accounts[j].User {
+			return accounts[i].User < accounts[j].User
+		}
+		return accounts[i].Host < accounts[j].Host
+	})
+
+	res := &result{columns: []string{"User", "Host"}}
+	for _, account := range accounts {
+		res.values = append(res.values, []driver.Value{account.User, account.Host})
+	}
+
+	return res
+}
+
+func (s *Server) schemaNames() []string {
+	names := []string{}
+	for name := range s.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (s *Server) selectSchemas() *result {
+	res := &result{columns: []string{"SCHEMA_NAME"}}
+	for _, name := range s.schemaNames() {
+		res.values = append(res.values, []driver.Value{name})
+	}
+
+	return res
+}
+
+func (s *Server) selectSchemaObjects() *result {
+	res := &result{columns: []string{"TABLE_SCHEMA", "TABLE_NAME"}}
+	for _, schema := range s.schemaNames() {
+		for _, object := range s.Schemas[schema] {
+			res.values = append(res.values, []driver.Value{schema, object})
+		}
+	}
+
+	return res
+}
+
+func (s *Server) selectPlugin(name driver.Value) *result {
+	res := &result{columns: []string{"PLUGIN_STATUS", "PLUGIN_VERSION"}}
+	if n, ok := name.(string); ok {
+		if plugin, ok := s.Plugins[n]; ok {
+			res.values = append(res.values, []driver.Value{plugin.Status, plugin.Version})
+		}
+	}
+
+	return res
+}
+
+func (s *Server) selectStatementSummary() *result {
+	res := &result{columns: []string{"USER", "HOST", "EVENT_NAME", "COUNT_STAR", "SUM_ERRORS"}}
+	for _, summary := range s.StatementSummary {
+		if summary.Count > 0 {
+			res.values = append(res.values, []driver.Value{summary.User, summary.Host, summary.EventName, summary.Count, summary.Errors})
+		}
+	}
+
+	return res
+}
+
+func (s *Server) selectStatementAnalysis() (*result, error) {
+	if _, ok := s.Schemas["sys"]; !ok {
+		return nil, &mysql.MySQLError{Number: 1049, Message: "Unknown database 'sys'"}
+	}
+
+	res := &result{columns: []string{"db", "query", "exec_count"}}
+	for _, analysis := range s.StatementAnalysis {
+		var db driver.Value
+		if analysis.Db != "" {
+			db = analysis.Db
+		}
+		res.values = append(res.values, []driver.Value{db, analysis.Query, analysis.ExecCount})
+	}
+
+	return res, nil
+}
+
+// showVariables implements SHOW GLOBAL VARIABLES LIKE, only trailing %
+// wildcards are supported.
+func (s *Server) showVariables(pattern string) *result {
+	prefix, wildcard := strings.CutSuffix(pattern, "%")
+
+	names := []string{}
+	for name := range s.Variables {
+		if name == pattern || (wildcard && strings.HasPrefix(name, prefix)) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	res := &result{columns: []string{"Variable_name", "Value"}}
+	for _, name := range names {
+		res.values = append(res.values, []driver.Value{name, s.Variables[name]})
+	}
+
+	return res
+}
+
+func nullable(s *string) driver.Value {
+	if s == nil {
+		return nil
+	}
+
+	return *s
+}
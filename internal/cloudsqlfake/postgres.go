@@ -0,0 +1,801 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudsqlfake
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/lib/pq"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ driver.Connector      = &postgresConnector{}
+	_ driver.Conn           = &postgresConn{}
+	_ driver.ExecerContext  = &postgresConn{}
+	_ driver.QueryerContext = &postgresConn{}
+	_ driver.Stmt           = &postgresStmt{}
+)
+
+var (
+	showSetting     = regexp.MustCompile(`^SHOW ([\w.]+)$`)
+	createExtension = regexp.MustCompile(`^CREATE EXTENSION IF NOT EXISTS (\w+)(?: VERSION '([^']*)')?$`)
+	updateExtension = regexp.MustCompile(`^ALTER EXTENSION (\w+) UPDATE(?: TO '([^']*)')?$`)
+	dropExtension   = regexp.MustCompile(`^DROP EXTENSION IF EXISTS (\w+)$`)
+
+	// the identifiers are quoted by the provider, the values are literals
+	alterRole     = regexp.MustCompile(`^ALTER ROLE "((?:[^"]|"")+)"(?: IN DATABASE "((?:[^"]|"")+)")? (?:SET ([\w.]+) = '((?:[^']|'')*)'|RESET ([\w.]+))$`)
+	alterDatabase = regexp.MustCompile(`^ALTER DATABASE "((?:[^"]|"")+)" (?:SET ([\w.]+) = '((?:[^']|'')*)'|RESET ([\w.]+))$`)
+	grantObject   = regexp.MustCompile(`^(GRANT|REVOKE) (\w+)(?: \(([^)]*)\))? ON "((?:[^"]|"")+)"\."((?:[^"]|"")+)" (?:TO|FROM) "((?:[^"]|"")+)"$`)
+	quotedIdent   = regexp.MustCompile(`"((?:[^"]|"")+)"`)
+)
+
+// postgresStatements maps the normalized form of the supported statements to
+// their implementation, like statements does for mysql.
+var postgresStatements = map[string]func(c *postgresConn, args []driver.Value) (*result, error){
+	"SELECT extversion FROM pg_extension WHERE extname = 'pgaudit'": func(c *postgresConn, _ []driver.Value) (*result, error) {
+		return c.server.selectExtension(c.database), nil
+	},
+	"SELECT default_version FROM pg_available_extensions WHERE name = 'pgaudit'": func(c *postgresConn, _ []driver.Value) (*result, error) {
+		return c.server.selectDefaultVersion(), nil
+	},
+	"SELECT s.setconfig FROM pg_db_role_setting s LEFT JOIN pg_roles r ON r.oid = s.setrole LEFT JOIN pg_database d ON d.oid = s.setdatabase WHERE COALESCE(r.rolname, '') = $1 AND COALESCE(d.datname, '') = $2": func(c *postgresConn, args []driver.Value) (*result, error) {
+		role, _ := args[0].(string)
+		database, _ := args[1].(string)
+		return c.server.selectRoleSetting(SettingScope{Role: role, Database: database}), nil
+	},
+	"SELECT current_setting($1, true)": func(c *postgresConn, args []driver.Value) (*result, error) {
+		name, _ := args[0].(string)
+		return c.server.selectCurrentSetting(c.database, name), nil
+	},
+	"SELECT current_database()": func(c *postgresConn, _ []driver.Value) (*result, error) {
+		return &result{columns: []string{"current_database"}, values: [][]driver.Value{{c.database}}}, nil
+	},
+	"SELECT COALESCE(r.rolname, ''), COALESCE(d.datname, ''), s.setconfig FROM pg_db_role_setting s LEFT JOIN pg_roles r ON r.oid = s.setrole LEFT JOIN pg_database d ON d.oid = s.setdatabase ORDER BY 1, 2": func(c *postgresConn, _ []driver.Value) (*result, error) {
+		return c.server.selectRoleSettings(), nil
+	},
+	"SELECT privilege_type FROM information_schema.role_table_grants WHERE grantee = $1 AND table_schema = $2 AND table_name = $3": func(c *postgresConn, args []driver.Value) (*result, error) {
+		return c.server.selectTableGrants(c.database, args), nil
+	},
+	"SELECT column_name, privilege_type FROM information_schema.column_privileges WHERE grantee = $1 AND table_schema = $2 AND table_name = $3": func(c *postgresConn, args []driver.Value) (*result, error) {
+		return c.server.selectColumnGrants(c.database, args), nil
+	},
+	"SELECT table_schema, table_name, privilege_type FROM information_schema.role_table_grants WHERE grantee = $1 ORDER BY 1, 2, 3": func(c *postgresConn, args []driver.Value) (*result, error) {
+		return c.server.selectAllTableGrants(c.database, args[0]), nil
+	},
+	"SELECT table_schema, table_name, column_name, privilege_type FROM information_schema.column_privileges WHERE grantee = $1 ORDER BY 1, 2, 3, 4": func(c *postgresConn, args []driver.Value) (*result, error) {
+		return c.server.selectAllColumnGrants(c.database, args[0]), nil
+	},
+}
+
+// SettingScope is a row of pg_db_role_setting, an empty role or database
+// applies to all of them.
+type SettingScope struct {
+	Role     string
+	Database string
+}
+
+// Table is a table of one of the databases that privileges can be granted
+// on.
+type Table struct {
+	Database string
+	Schema   string
+	Name     string
+	Columns  []string
+}
+
+// Grant is a privilege held by a role on a table, or on one of its columns
+// when Column is set.
+type Grant struct {
+	Database  string
+	Schema    string
+	Table     string
+	Role      string
+	Privilege string
+	Column    string
+}
+
+// PostgresServer holds the state of a fake Cloud SQL for PostgreSQL
+// instance. Like Server, the exported fields can only be changed before the
+// instance is first used.
+type PostgresServer struct {
+	// Flags are the settings returned by SHOW, e.g., the
+	// cloudsql.enable_pgaudit database flag.
+	Flags map[string]string
+
+	// Databases are the databases that can be connected to.
+	Databases []string
+
+	// PgauditVersions are the versions of the pgaudit extension that can be
+	// installed, the last one is the default version.
+	PgauditVersions []string
+
+	// Roles are the roles that settings can be altered for.
+	Roles []string
+
+	// Tables are the tables that privileges can be granted on.
+	Tables []Table
+
+	mu         sync.Mutex
+	extensions map[string]string
+	settings   map[SettingScope]map[string]string
+	grants     map[Grant]bool
+}
+
+// NewPostgres returns an instance with the postgres and app databases, an
+// orders table in app and pgaudit enabled but not installed.
+func NewPostgres() *PostgresServer {
+	return &PostgresServer{
+		Flags: map[string]string{
+			"cloudsql.enable_pgaudit": "on",
+		},
+		Databases:       []string{"postgres", "app"},
+		PgauditVersions: []string{"1.6.2", "1.7"},
+		Roles:           []string{"postgres", "app_user", "auditor"},
+		Tables: []Table{
+			{Database: "app", Schema: "public", Name: "orders", Columns: []string{"id", "customer", "total"}},
+		},
+		extensions: make(map[string]string),
+		settings:   make(map[SettingScope]map[string]string),
+		grants:     make(map[Grant]bool),
+	}
+}
+
+// DB returns a connection pool to a database of the instance, connecting
+// fails if the database doesn't exist.
+func (s *PostgresServer) DB(database string) *sql.DB {
+	return sql.OpenDB(&postgresConnector{server: s, database: database})
+}
+
+// InstallPgaudit creates the pgaudit extension in a database, as if it was
+// created outside of terraform.
+func (s *PostgresServer) InstallPgaudit(database, version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.extensions[database] = version
+}
+
+// Pgaudit returns the version of the pgaudit extension installed in a
+// database.
+func (s *PostgresServer) Pgaudit(database string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	version, ok := s.extensions[database]
+	return version, ok
+}
+
+// SetSetting stores a role or database setting, as if it was set outside of
+// terraform.
+func (s *PostgresServer) SetSetting(scope SettingScope, name, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.settings[scope] == nil {
+		s.settings[scope] = make(map[string]string)
+	}
+	s.settings[scope][name] = value
+}
+
+// Settings returns the settings stored for a role and database scope.
+func (s *PostgresServer) Settings(scope SettingScope) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings := make(map[string]string)
+	for name, value := range s.settings[scope] {
+		settings[name] = value
+	}
+
+	return settings
+}
+
+// AddGrant grants a privilege, as if it was granted outside of terraform.
+func (s *PostgresServer) AddGrant(grant Grant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.grants[grant] = true
+}
+
+// RevokeGrant revokes a privilege, as if it was revoked outside of
+// terraform.
+func (s *PostgresServer) RevokeGrant(grant Grant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.grants, grant)
+}
+
+// Grants returns the privileges held by a role on a table, sorted by
+// privilege and column.
+func (s *PostgresServer) Grants(database, schema, table, role string) []Grant {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.roleGrants(database, []driver.Value{role, schema, table})
+}
+
+type postgresConnector struct {
+	server   *PostgresServer
+	database string
+}
+
+func (c *postgresConnector) Connect(_ context.Context) (driver.Conn, error) {
+	for _, name := range c.server.Databases {
+		if name == c.database {
+			return &postgresConn{server: c.server, database: c.database}, nil
+		}
+	}
+
+	return nil, &pq.Error{
+		Code:    "3D000",
+		Message: fmt.Sprintf("database %q does not exist", c.database),
+	}
+}
+
+func (c *postgresConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+// postgresConn is a session on a database of the fake instance.
+type postgresConn struct {
+	server   *PostgresServer
+	database string
+}
+
+func (c *postgresConn) Prepare(query string) (driver.Stmt, error) {
+	return &postgresStmt{conn: c, query: query}, nil
+}
+
+func (c *postgresConn) Close() error {
+	return nil
+}
+
+func (c *postgresConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("cloudsqlfake: transactions are not supported")
+}
+
+func (c *postgresConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.run(query, namedValues(args))
+}
+
+func (c *postgresConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	res, err := c.run(query, namedValues(args))
+	if err != nil {
+		return nil, err
+	}
+
+	return &rows{result: res}, nil
+}
+
+func (c *postgresConn) run(query string, args []driver.Value) (*result, error) {
+	query = normalize(query)
+
+	if run, ok := postgresStatements[query]; ok {
+		if want := strings.Count(query, "$"); want != len(args) {
+			return nil, fmt.Errorf("cloudsqlfake: expected %d arguments, got %d", want, len(args))
+		}
+
+		return run(c, args)
+	}
+
+	if m := showSetting.FindStringSubmatch(query); m != nil {
+		return c.server.show(m[1])
+	}
+
+	if m := createExtension.FindStringSubmatch(query); m != nil {
+		return c.server.createExtension(c.database, m[1], m[2])
+	}
+
+	if m := updateExtension.FindStringSubmatch(query); m != nil {
+		return c.server.updateExtension(c.database, m[1], m[2])
+	}
+
+	if m := dropExtension.FindStringSubmatch(query); m != nil {
+		return c.server.dropExtension(c.database, m[1])
+	}
+
+	if m := alterRole.FindStringSubmatch(query); m != nil {
+		scope := SettingScope{Role: unquoteIdent(m[1]), Database: unquoteIdent(m[2])}
+		return c.server.alterSetting(scope, m[3], m[4], m[5])
+	}
+
+	if m := alterDatabase.FindStringSubmatch(query); m != nil {
+		return c.server.alterSetting(SettingScope{Database: unquoteIdent(m[1])}, m[2], m[3], m[4])
+	}
+
+	if m := grantObject.FindStringSubmatch(query); m != nil {
+		var columns []string
+		for _, column := range quotedIdent.FindAllStringSubmatch(m[3], -1) {
+			columns = append(columns, unquoteIdent(column[1]))
+		}
+
+		grant := Grant{
+			Database:  c.database,
+			Schema:    unquoteIdent(m[4]),
+			Table:     unquoteIdent(m[5]),
+			Role:      unquoteIdent(m[6]),
+			Privilege: m[2],
+		}
+		return c.server.grant(m[1] == "GRANT", grant, columns)
+	}
+
+	return nil, fmt.Errorf("cloudsqlfake: unsupported statement: %s", query)
+}
+
+type postgresStmt struct {
+	conn  *postgresConn
+	query string
+}
+
+func (s *postgresStmt) Close() error {
+	return nil
+}
+
+func (s *postgresStmt) NumInput() int {
+	return -1
+}
+
+func (s *postgresStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.run(s.query, args)
+}
+
+func (s *postgresStmt) Query(args []driver.Value) (driver.Rows, error) {
+	res, err := s.conn.run(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rows{result: res}, nil
+}
+
+func (s *PostgresServer) show(name string) (*result, error) {
+	value, ok := s.Flags[name]
+	if !ok {
+		return nil, &pq.Error{
+			Code:    "42704",
+			Message: fmt.Sprintf("unrecognized configuration parameter %q", name),
+		}
+	}
+
+	return &result{columns: []string{name}, values: [][]driver.Value{{value}}}, nil
+}
+
+func (s *PostgresServer) selectExtension(database string) *result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := &result{columns: []string{"extversion"}}
+	if version, ok := s.extensions[database]; ok {
+		res.values = append(res.values, []driver.Value{version})
+	}
+
+	return res
+}
+
+func (s *PostgresServer) selectDefaultVersion() *result {
+	res := &result{columns: []string{"default_version"}}
+	if len(s.PgauditVersions) > 0 {
+		res.values = append(res.values, []driver.Value{s.PgauditVersions[len(s.PgauditVersions)-1]})
+	}
+
+	return res
+}
+
+func (s *PostgresServer) checkExtension(name, version string) error {
+	if name != "pgaudit" || len(s.PgauditVersions) == 0 {
+		return &pq.Error{
+			Code:    "0A000",
+			Message: fmt.Sprintf("extension %q is not available", name),
+		}
+	}
+
+	if !slices.Contains(s.PgauditVersions, version) {
+		return &pq.Error{
+			Code:    "22023",
+			Message: fmt.Sprintf("extension %q has no installation script nor update path for version %q", name, version),
+		}
+	}
+
+	return nil
+}
+
+func (s *PostgresServer) createExtension(database, name, version string) (*result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if version == "" && len(s.PgauditVersions) > 0 {
+		version = s.PgauditVersions[len(s.PgauditVersions)-1]
+	}
+
+	if err := s.checkExtension(name, version); err != nil {
+		return nil, err
+	}
+
+	// like on cloudsql, the library is only preloaded with the flag on
+	if !strings.EqualFold(s.Flags["cloudsql.enable_pgaudit"], "on") {
+		return nil, &pq.Error{
+			Code:    "XX000",
+			Message: "pgaudit must be loaded via shared_preload_libraries",
+		}
+	}
+
+	if _, ok := s.extensions[database]; !ok {
+		s.extensions[database] = version
+	}
+
+	return &result{}, nil
+}
+
+func (s *PostgresServer) updateExtension(database, name, version string) (*result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.extensions[database]; !ok || name != "pgaudit" {
+		return nil, &pq.Error{
+			Code:    "42704",
+			Message: fmt.Sprintf("extension %q does not exist", name),
+		}
+	}
+
+	if version == "" && len(s.PgauditVersions) > 0 {
+		version = s.PgauditVersions[len(s.PgauditVersions)-1]
+	}
+
+	if err := s.checkExtension(name, version); err != nil {
+		return nil, err
+	}
+
+	s.extensions[database] = version
+
+	return &result{}, nil
+}
+
+func (s *PostgresServer) dropExtension(database, name string) (*result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if name == "pgaudit" {
+		delete(s.extensions, database)
+	}
+
+	return &result{}, nil
+}
+
+// alterSetting sets name to value, or resets the reset setting, for the
+// scope.
+func (s *PostgresServer) alterSetting(scope SettingScope, name, value, reset string) (*result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if scope.Role != "" && !slices.Contains(s.Roles, scope.Role) {
+		return nil, &pq.Error{
+			Code:    "42704",
+			Message: fmt.Sprintf("role %q does not exist", scope.Role),
+		}
+	}
+
+	if scope.Database != "" && !slices.Contains(s.Databases, scope.Database) {
+		return nil, &pq.Error{
+			Code:    "3D000",
+			Message: fmt.Sprintf("database %q does not exist", scope.Database),
+		}
+	}
+
+	if reset != "" {
+		delete(s.settings[scope], reset)
+		if len(s.settings[scope]) == 0 {
+			delete(s.settings, scope)
+		}
+
+		return &result{}, nil
+	}
+
+	if s.settings[scope] == nil {
+		s.settings[scope] = make(map[string]string)
+	}
+	s.settings[scope][name] = strings.ReplaceAll(value, "''", "'")
+
+	return &result{}, nil
+}
+
+func (s *PostgresServer) selectRoleSetting(scope SettingScope) *result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := &result{columns: []string{"setconfig"}}
+	if settings, ok := s.settings[scope]; ok {
+		res.values = append(res.values, []driver.Value{settingArray(settings)})
+	}
+
+	return res
+}
+
+// settingArray formats the settings as the text form of the setconfig
+// array.
+func settingArray(settings map[string]string) []byte {
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	elements := make([]string, 0, len(names))
+	for _, name := range names {
+		entry := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name + "=" + settings[name])
+		elements = append(elements, `"`+entry+`"`)
+	}
+
+	return []byte("{" + strings.Join(elements, ",") + "}")
+}
+
+// selectCurrentSetting returns a setting like current_setting does, a
+// database setting overrides the instance flag.
+func (s *PostgresServer) selectCurrentSetting(database, name string) *result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var value driver.Value
+	if flag, ok := s.Flags[name]; ok {
+		value = flag
+	}
+	if setting, ok := s.settings[SettingScope{Database: database}][name]; ok {
+		value = setting
+	}
+
+	return &result{columns: []string{"current_setting"}, values: [][]driver.Value{{value}}}
+}
+
+// selectRoleSettings lists every row of pg_db_role_setting, sorted by role
+// and database.
+func (s *PostgresServer) selectRoleSettings() *result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scopes := make([]SettingScope, 0, len(s.settings))
+	for scope := range s.settings {
+		scopes = append(scopes, scope)
+	}
+	slices.SortFunc(scopes, func(a, b SettingScope) int {
+		if c := strings.Compare(a.Role, b.Role); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Database, b.Database)
+	})
+
+	res := &result{columns: []string{"rolname", "datname", "setconfig"}}
+	for _, scope := range scopes {
+		res.values = append(res.values, []driver.Value{scope.Role, scope.Database, settingArray(s.settings[scope])})
+	}
+
+	return res
+}
+
+func (s *PostgresServer) table(database, schema, name string) (Table, bool) {
+	for _, table := range s.Tables {
+		if table.Database == database && table.Schema == schema && table.Name == name {
+			return table, true
+		}
+	}
+
+	return Table{}, false
+}
+
+// grant grants or revokes a privilege on the table, or on the columns when
+// there are any. Like postgresql, revoking a table privilege revokes it on
+// all of the columns as well.
+func (s *PostgresServer) grant(grant bool, g Grant, columns []string) (*result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, ok := s.table(g.Database, g.Schema, g.Table)
+	if !ok {
+		return nil, &pq.Error{
+			Code:    "42P01",
+			Message: fmt.Sprintf("relation \"%s.%s\" does not exist", g.Schema, g.Table),
+		}
+	}
+
+	if !slices.Contains(s.Roles, g.Role) {
+		return nil, &pq.Error{
+			Code:    "42704",
+			Message: fmt.Sprintf("role %q does not exist", g.Role),
+		}
+	}
+
+	switch g.Privilege {
+	case "SELECT", "INSERT", "UPDATE":
+	case "DELETE":
+		if len(columns) > 0 {
+			return nil, &pq.Error{
+				Code:    "0LP01",
+				Message: "invalid privilege type DELETE for column",
+			}
+		}
+	default:
+		return nil, &pq.Error{
+			Code:    "42601",
+			Message: fmt.Sprintf("unrecognized privilege type %q", g.Privilege),
+		}
+	}
+
+	for _, column := range columns {
+		if !slices.Contains(table.Columns, column) {
+			return nil, &pq.Error{
+				Code:    "42703",
+				Message: fmt.Sprintf("column %q of relation %q does not exist", column, g.Table),
+			}
+		}
+	}
+
+	if len(columns) == 0 {
+		if grant {
+			s.grants[g] = true
+			return &result{}, nil
+		}
+
+		// the table privilege is revoked along with the column ones
+		columns = append(slices.Clone(table.Columns), "")
+	}
+
+	for _, column := range columns {
+		g.Column = column
+		if grant {
+			s.grants[g] = true
+		} else {
+			delete(s.grants, g)
+		}
+	}
+
+	return &result{}, nil
+}
+
+// roleGrants returns the grants matching the grantee, table_schema and
+// table_name arguments of the information_schema queries.
+func (s *PostgresServer) roleGrants(database string, args []driver.Value) []Grant {
+	role, _ := args[0].(string)
+	schema, _ := args[1].(string)
+	table, _ := args[2].(string)
+
+	var grants []Grant
+	for grant := range s.grants {
+		if grant.Database == database && grant.Schema == schema && grant.Table == table && grant.Role == role {
+			grants = append(grants, grant)
+		}
+	}
+	slices.SortFunc(grants, func(a, b Grant) int {
+		return strings.Compare(a.Privilege+" "+a.Column, b.Privilege+" "+b.Column)
+	})
+
+	return grants
+}
+
+func (s *PostgresServer) selectTableGrants(database string, args []driver.Value) *result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := &result{columns: []string{"privilege_type"}}
+	for _, grant := range s.roleGrants(database, args) {
+		if grant.Column == "" {
+			res.values = append(res.values, []driver.Value{grant.Privilege})
+		}
+	}
+
+	return res
+}
+
+// selectColumnGrants lists the column privileges, which like in
+// information_schema.column_privileges include the table privileges for
+// every column.
+func (s *PostgresServer) selectColumnGrants(database string, args []driver.Value) *result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := &result{columns: []string{"column_name", "privilege_type"}}
+	seen := make(map[[2]string]bool)
+	for _, grant := range s.roleGrants(database, args) {
+		if grant.Privilege == "DELETE" {
+			continue
+		}
+
+		columns := []string{grant.Column}
+		if grant.Column == "" {
+			table, _ := s.table(database, grant.Schema, grant.Table)
+			columns = table.Columns
+		}
+
+		for _, column := range columns {
+			if key := [2]string{column, grant.Privilege}; !seen[key] {
+				seen[key] = true
+				res.values = append(res.values, []driver.Value{column, grant.Privilege})
+			}
+		}
+	}
+
+	return res
+}
+
+// allGrants returns the grants of a role on every table of the database,
+// sorted by table, privilege and column.
+func (s *PostgresServer) allGrants(database string, role driver.Value) []Grant {
+	var grants []Grant
+	for grant := range s.grants {
+		if grant.Database == database && grant.Role == role {
+			grants = append(grants, grant)
+		}
+	}
+	slices.SortFunc(grants, func(a, b Grant) int {
+		return strings.Compare(
+			strings.Join([]string{a.Schema, a.Table, a.Privilege, a.Column}, " "),
+			strings.Join([]string{b.Schema, b.Table, b.Privilege, b.Column}, " "),
+		)
+	})
+
+	return grants
+}
+
+func (s *PostgresServer) selectAllTableGrants(database string, role driver.Value) *result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := &result{columns: []string{"table_schema", "table_name", "privilege_type"}}
+	for _, grant := range s.allGrants(database, role) {
+		if grant.Column == "" {
+			res.values = append(res.values, []driver.Value{grant.Schema, grant.Table, grant.Privilege})
+		}
+	}
+
+	return res
+}
+
+// selectAllColumnGrants is selectColumnGrants for every table of the
+// database.
+func (s *PostgresServer) selectAllColumnGrants(database string, role driver.Value) *result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := &result{columns: []string{"table_schema", "table_name", "column_name", "privilege_type"}}
+	seen := make(map[Grant]bool)
+	for _, grant := range s.allGrants(database, role) {
+		if grant.Privilege == "DELETE" {
+			continue
+		}
+
+		columns := []string{grant.Column}
+		if grant.Column == "" {
+			table, _ := s.table(database, grant.Schema, grant.Table)
+			columns = table.Columns
+		}
+
+		for _, column := range columns {
+			key := grant
+			key.Column = column
+			if !seen[key] {
+				seen[key] = true
+				res.values = append(res.values, []driver.Value{grant.Schema, grant.Table, column, grant.Privilege})
+			}
+		}
+	}
+
+	// like ORDER BY 1, 2, 3, 4
+	slices.SortFunc(res.values, func(a, b []driver.Value) int {
+		return strings.Compare(fmt.Sprintln(a), fmt.Sprintln(b))
+	})
+
+	return res
+}
+
+func unquoteIdent(s string) string {
+	return strings.ReplaceAll(s, `""`, `"`)
+}
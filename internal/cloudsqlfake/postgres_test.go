@@ -0,0 +1,129 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudsqlfake
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestPgauditExtension(t *testing.T) {
+	server := NewPostgres()
+	db := server.DB("app")
+
+	if _, err := db.Exec("CREATE EXTENSION IF NOT EXISTS pgaudit VERSION '1.0'"); err == nil {
+		t.Fatal("expected unknown versions to fail")
+	}
+
+	if _, err := db.Exec("CREATE EXTENSION IF NOT EXISTS pgaudit"); err != nil {
+		t.Fatal(err)
+	}
+	if version, _ := server.Pgaudit("app"); version != "1.7" {
+		t.Fatalf("expected the default version to be installed, got %q", version)
+	}
+	if _, ok := server.Pgaudit("postgres"); ok {
+		t.Fatal("expected the extension to only be installed in app")
+	}
+
+	var version string
+	if err := db.QueryRow("SELECT extversion FROM pg_extension WHERE extname = 'pgaudit'").Scan(&version); err != nil || version != "1.7" {
+		t.Fatalf("expected version 1.7, got %q: %v", version, err)
+	}
+
+	if _, err := db.Exec("DROP EXTENSION IF EXISTS pgaudit"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := server.Pgaudit("app"); ok {
+		t.Fatal("expected the extension to be dropped")
+	}
+}
+
+func TestPgauditDisabled(t *testing.T) {
+	server := NewPostgres()
+	server.Flags["cloudsql.enable_pgaudit"] = "off"
+
+	var flag string
+	if err := server.DB("postgres").QueryRow("SHOW cloudsql.enable_pgaudit").Scan(&flag); err != nil || flag != "off" {
+		t.Fatalf("expected the flag to be off, got %q: %v", flag, err)
+	}
+
+	if _, err := server.DB("postgres").Exec("CREATE EXTENSION IF NOT EXISTS pgaudit"); err == nil {
+		t.Fatal("expected creating the extension to fail")
+	}
+}
+
+func TestPostgresMissingDatabase(t *testing.T) {
+	var pqErr *pq.Error
+	_, err := NewPostgres().DB("missing").Exec("SHOW cloudsql.enable_pgaudit")
+	if !errors.As(err, &pqErr) || pqErr.Code != "3D000" {
+		t.Fatalf("expected an invalid catalog name error, got %v", err)
+	}
+}
+
+func TestRoleSettings(t *testing.T) {
+	server := NewPostgres()
+	db := server.DB("postgres")
+
+	if _, err := db.Exec(`ALTER ROLE "auditor" IN DATABASE "app" SET pgaudit.log = 'read, ''write'''`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`ALTER ROLE "missing" SET pgaudit.log = 'read'`); err == nil {
+		t.Fatal("expected altering a missing role to fail")
+	}
+
+	var config []string
+	err := db.QueryRow(`SELECT s.setconfig FROM pg_db_role_setting s
+LEFT JOIN pg_roles r ON r.oid = s.setrole
+LEFT JOIN pg_database d ON d.oid = s.setdatabase
+WHERE COALESCE(r.rolname, '') = $1 AND COALESCE(d.datname, '') = $2`, "auditor", "app").Scan(pq.Array(&config))
+	if err != nil || len(config) != 1 || config[0] != "pgaudit.log=read, 'write'" {
+		t.Fatalf("unexpected setconfig %q: %v", config, err)
+	}
+
+	if _, err := db.Exec(`ALTER ROLE "auditor" IN DATABASE "app" RESET pgaudit.log`); err != nil {
+		t.Fatal(err)
+	}
+	if settings := server.Settings(SettingScope{Role: "auditor", Database: "app"}); len(settings) != 0 {
+		t.Fatalf("expected the settings to be reset, got %v", settings)
+	}
+}
+
+func TestObjectGrants(t *testing.T) {
+	server := NewPostgres()
+	db := server.DB("app")
+
+	var pqErr *pq.Error
+	_, err := db.Exec(`GRANT SELECT ON "public"."missing" TO "auditor"`)
+	if !errors.As(err, &pqErr) || pqErr.Code != "42P01" {
+		t.Fatalf("expected an undefined table error, got %v", err)
+	}
+
+	if _, err := db.Exec(`GRANT SELECT ON "public"."orders" TO "auditor"`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`GRANT UPDATE ("total") ON "public"."orders" TO "auditor"`); err != nil {
+		t.Fatal(err)
+	}
+
+	var columns int
+	rows, err := db.Query("SELECT column_name, privilege_type FROM information_schema.column_privileges WHERE grantee = $1 AND table_schema = $2 AND table_name = $3", "auditor", "public", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		columns++
+	}
+	if columns != 4 {
+		t.Fatalf("expected the table privilege on every column and the column privilege, got %d rows", columns)
+	}
+
+	if _, err := db.Exec(`REVOKE SELECT ON "public"."orders" FROM "auditor"`); err != nil {
+		t.Fatal(err)
+	}
+	if grants := server.Grants("app", "public", "orders", "auditor"); len(grants) != 1 || grants[0].Column != "total" {
+		t.Fatalf("expected only the column privilege to be left, got %v", grants)
+	}
+}
@@ -0,0 +1,372 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+// Package cloudsqlfake is an in-memory stand-in for Cloud SQL for MySQL and
+// PostgreSQL instances. It implements just enough of the audit_log_rules
+// table, the mysql.cloudsql_*_audit_rule procedures, the pgaudit extension
+// and the catalog queries used by the provider to run the tests offline.
+package cloudsqlfake
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Rule is a row of the audit_log_rules table.
+type Rule struct {
+	ID        int64
+	Username  string
+	Dbname    string
+	Object    string
+	Operation string
+	OpResult  string
+}
+
+// Metadata is a row of the provider managed tf_audit_rule_metadata table,
+// nil fields are NULL.
+type Metadata struct {
+	RuleID      int64
+	Description *string
+	Owner       *string
+	Ticket      *string
+}
+
+// Account is a row of the mysql.user table.
+type Account struct {
+	User string
+	Host string
+}
+
+// Plugin is a row of the information_schema.PLUGINS table.
+type Plugin struct {
+	Status  string
+	Version string
+}
+
+// StatementSummary is a row of the
+// performance_schema.events_statements_summary_by_account_by_event_name
+// table.
+type StatementSummary struct {
+	User      string
+	Host      string
+	EventName string
+	Count     int64
+	Errors    int64
+}
+
+// StatementAnalysis is a row of the sys.x$statement_analysis view, an empty
+// Db is NULL.
+type StatementAnalysis struct {
+	Db        string
+	Query     string
+	ExecCount int64
+}
+
+// Server holds the state of a fake instance. The exported fields can be
+// changed to shape the instance but only before it is first used.
+type Server struct {
+	// Accounts are returned from mysql.user.
+	Accounts []Account
+
+	// Schemas maps the databases to the tables, views and routines they
+	// contain.
+	Schemas map[string][]string
+
+	// Plugins are returned from information_schema.PLUGINS.
+	Plugins map[string]Plugin
+
+	// Variables are returned by SHOW GLOBAL VARIABLES.
+	Variables map[string]string
+
+	// StatementSummary is returned from the statement summary by account
+	// and event name.
+	StatementSummary []StatementSummary
+
+	// StatementAnalysis is returned from sys.x$statement_analysis, which
+	// fails when the sys schema isn't in Schemas.
+	StatementAnalysis []StatementAnalysis
+
+	// Uptime is the Uptime global status in seconds.
+	Uptime int64
+
+	// DenyCreateTable makes CREATE TABLE fail like it does for users
+	// without the CREATE privilege on the mysql schema.
+	DenyCreateTable bool
+
+	mu            sync.Mutex
+	nextID        int64
+	rules         map[int64]Rule
+	active        []Rule
+	metadataTable bool
+	metadata      map[int64]Metadata
+}
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ driver.Connector = &Server{}
+
+// New returns an empty instance with the audit log plugin installed and
+// enabled and a root@% account.
+func New() *Server {
+	return &Server{
+		Accounts: []Account{{User: "root", Host: "%"}},
+		Schemas: map[string][]string{
+			"information_schema": nil,
+			"mysql":              {"audit_log_rules", "user"},
+			"performance_schema": nil,
+			"sys":                nil,
+		},
+		Plugins: map[string]Plugin{
+			"cloudsql_mysql_audit": {Status: "ACTIVE", Version: "1.0"},
+		},
+		Variables: map[string]string{
+			"cloudsql_mysql_audit_log":                  "ON",
+			"cloudsql_mysql_audit_data_masking_cmds":    "create,update",
+			"cloudsql_mysql_audit_log_write_period":     "500",
+			"cloudsql_mysql_audit_max_query_length":     "-1",
+			"cloudsql_mysql_audit_event_split_max_size": "0",
+		},
+		Uptime:   3600,
+		nextID:   1,
+		rules:    make(map[int64]Rule),
+		metadata: make(map[int64]Metadata),
+	}
+}
+
+// DB returns a connection pool to the instance.
+func (s *Server) DB() *sql.DB {
+	return sql.OpenDB(s)
+}
+
+// Connect implements driver.Connector, each connection has its own session
+// variables.
+func (s *Server) Connect(_ context.Context) (driver.Conn, error) {
+	return &conn{server: s, vars: make(map[string]driver.Value)}, nil
+}
+
+// Driver implements driver.Connector.
+func (s *Server) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+// AddRule stores a rule and makes it active, as if it was created outside of
+// terraform, and returns its id.
+func (s *Server) AddRule(rule Rule) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule.ID = s.nextID
+	s.nextID++
+	s.rules[rule.ID] = rule
+	s.reload()
+
+	return rule.ID
+}
+
+// Rules returns the stored rules sorted by id.
+func (s *Server) Rules() []Rule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sortedRules()
+}
+
+// ActiveRules returns the rules as of the last reload, these are the rules
+// the audit plugin is enforcing.
+func (s *Server) ActiveRules() []Rule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Rule(nil), s.active...)
+}
+
+// MetadataTableExists reports whether the provider created the
+// tf_audit_rule_metadata table.
+func (s *Server) MetadataTableExists() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.metadataTable
+}
+
+// Metadata returns the metadata stored for a rule.
+func (s *Server) Metadata(ruleID int64) (Metadata, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.metadata[ruleID]
+	return m, ok
+}
+
+func (s *Server) sortedRules() []Rule {
+	rules := make([]Rule, 0, len(s.rules))
+	for _, rule := range s.rules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	return rules
+}
+
+func (s *Server) reload() {
+	s.active = s.sortedRules()
+}
+
+// procedureResult is what the cloudsql procedures return in @outval and
+// @outmsg.
+type procedureResult struct {
+	outval int64
+	outmsg string
+}
+
+var procedureOK = procedureResult{outval: 0, outmsg: "OK"}
+
+func procedureFailed(format string, a ...interface{}) procedureResult {
+	return procedureResult{outval: 1, outmsg: fmt.Sprintf(format, a...)}
+}
+
+// procedures maps the supported procedures to their number of arguments,
+// including the reload mode and the two out variables.
+var procedures = map[string]int{
+	"cloudsql_create_audit_rule": 8,
+	"cloudsql_update_audit_rule": 9,
+	"cloudsql_delete_audit_rule": 4,
+	"cloudsql_reload_audit_rule": 3,
+}
+
+// call runs a procedure with the in arguments, the out variables have already
+// been split off by the caller.
+func (s *Server) call(name string, in []driver.Value) procedureResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch name {
+	case "cloudsql_create_audit_rule":
+		rule, res := ruleFromArgs(in[:5])
+		if res.outval != 0 {
+			return res
+		}
+
+		rule.ID = s.nextID
+		s.nextID++
+		s.rules[rule.ID] = rule
+
+		return s.afterChange(in[5])
+	case "cloudsql_update_audit_rule":
+		id, ok := intArg(in[0])
+		if !ok {
+			return procedureFailed("Invalid rule id")
+		}
+		if _, ok := s.rules[id]; !ok {
+			return procedureFailed("Rule with id %d does not exist", id)
+		}
+
+		rule, res := ruleFromArgs(in[1:6])
+		if res.outval != 0 {
+			return res
+		}
+
+		rule.ID = id
+		s.rules[id] = rule
+
+		return s.afterChange(in[6])
+	case "cloudsql_delete_audit_rule":
+		id, ok := intArg(in[0])
+		if !ok {
+			return procedureFailed("Invalid rule id")
+		}
+		if _, ok := s.rules[id]; !ok {
+			return procedureFailed("Rule with id %d does not exist", id)
+		}
+
+		delete(s.rules, id)
+
+		return s.afterChange(in[1])
+	case "cloudsql_reload_audit_rule":
+		if mode, ok := intArg(in[0]); !ok || mode != 1 {
+			return procedureFailed("Invalid reload mode")
+		}
+
+		s.reload()
+
+		return procedureOK
+	}
+
+	return procedureFailed("Unknown procedure %s", name)
+}
+
+// afterChange reloads the rules when the reload mode asks for it, otherwise
+// the change only takes effect after cloudsql_reload_audit_rule.
+func (s *Server) afterChange(reloadMode driver.Value) procedureResult {
+	mode, ok := intArg(reloadMode)
+	if !ok || (mode != 0 && mode != 1) {
+		return procedureFailed("Invalid reload mode")
+	}
+
+	if mode == 1 {
+		s.reload()
+	}
+
+	return procedureOK
+}
+
+func ruleFromArgs(args []driver.Value) (Rule, procedureResult) {
+	var fields [5]string
+	for i, arg := range args {
+		s, ok := arg.(string)
+		if !ok || s == "" {
+			return Rule{}, procedureFailed("Invalid argument %d", i+1)
+		}
+		fields[i] = s
+	}
+
+	rule := Rule{
+		Username:  fields[0],
+		Dbname:    fields[1],
+		Object:    fields[2],
+		Operation: fields[3],
+		OpResult:  fields[4],
+	}
+
+	switch rule.OpResult {
+	case "S", "U", "B", "E":
+	default:
+		return Rule{}, procedureFailed("Invalid op_result %q, must be one of S, U, B or E", rule.OpResult)
+	}
+
+	for _, user := range strings.Split(rule.Username, ",") {
+		if user != "*" && !strings.Contains(user, "@") {
+			return Rule{}, procedureFailed("Invalid user %q, must be in the user@host format", user)
+		}
+	}
+
+	return rule, procedureOK
+}
+
+// intArg converts an argument to an integer, like mysql does with numeric
+// strings.
+func intArg(v driver.Value) (int64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return v, true
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		return i, err == nil
+	}
+
+	return 0, false
+}
+
+// fakeDriver only exists to satisfy driver.Connector, connections have to be
+// made through the Server.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(_ string) (driver.Conn, error) {
+	return nil, errors.New("cloudsqlfake: open connections with sql.OpenDB(server)")
+}
@@ -0,0 +1,175 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudsqlfake
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func readOutVars(t *testing.T, conn *sql.Conn) (int64, string) {
+	t.Helper()
+
+	var outval int64
+	var outmsg string
+	if err := conn.QueryRowContext(context.Background(), "SELECT @outval, @outmsg").Scan(&outval, &outmsg); err != nil {
+		t.Fatalf("unable to read out variables: %s", err)
+	}
+
+	return outval, outmsg
+}
+
+func TestAuditRuleProcedures(t *testing.T) {
+	ctx := context.Background()
+	server := New()
+
+	conn, err := server.DB().Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, "CALL mysql.cloudsql_create_audit_rule(?, ?, ?, ?, ?, 1, @outval, @outmsg)", "user@%", "*", "*", "*", "B")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outval, outmsg := readOutVars(t, conn); outval != 0 {
+		t.Fatalf("expected create to succeed, got %d: %s", outval, outmsg)
+	}
+
+	var id int64
+	err = conn.QueryRowContext(ctx, "SELECT id FROM audit_log_rules WHERE username = ? AND dbname = ? AND object = ? AND operation = ? AND op_result = ?", "user@%", "*", "*", "*", "B").Scan(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without reloading the change isn't active yet
+	_, err = conn.ExecContext(ctx, "CALL mysql.cloudsql_update_audit_rule(?, ?, ?, ?, ?, ?, 0, @outval, @outmsg)", id, "user@%", "db", "*", "*", "S")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outval, outmsg := readOutVars(t, conn); outval != 0 {
+		t.Fatalf("expected update to succeed, got %d: %s", outval, outmsg)
+	}
+	if active := server.ActiveRules(); len(active) != 1 || active[0].OpResult != "B" {
+		t.Fatalf("expected the previous rule to be active, got %v", active)
+	}
+
+	_, err = conn.ExecContext(ctx, "CALL mysql.cloudsql_reload_audit_rule(1, @outval, @outmsg)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active := server.ActiveRules(); len(active) != 1 || active[0].OpResult != "S" || active[0].Dbname != "db" {
+		t.Fatalf("expected the updated rule to be active, got %v", active)
+	}
+
+	_, err = conn.ExecContext(ctx, "CALL mysql.cloudsql_delete_audit_rule(?, 1, @outval, @outmsg)", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules := server.Rules(); len(rules) != 0 {
+		t.Fatalf("expected no rules, got %v", rules)
+	}
+}
+
+func TestAuditRuleProcedureFailures(t *testing.T) {
+	ctx := context.Background()
+	server := New()
+
+	conn, err := server.DB().Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	cases := map[string]struct {
+		query string
+		args  []interface{}
+	}{
+		"invalid op_result": {
+			query: "CALL mysql.cloudsql_create_audit_rule(?, ?, ?, ?, ?, 1, @outval, @outmsg)",
+			args:  []interface{}{"user@%", "*", "*", "*", "X"},
+		},
+		"invalid user": {
+			query: "CALL mysql.cloudsql_create_audit_rule(?, ?, ?, ?, ?, 1, @outval, @outmsg)",
+			args:  []interface{}{"user", "*", "*", "*", "S"},
+		},
+		"missing rule update": {
+			query: "CALL mysql.cloudsql_update_audit_rule(?, ?, ?, ?, ?, ?, 1, @outval, @outmsg)",
+			args:  []interface{}{int64(42), "user@%", "*", "*", "*", "S"},
+		},
+		"missing rule delete": {
+			query: "CALL mysql.cloudsql_delete_audit_rule(?, 1, @outval, @outmsg)",
+			args:  []interface{}{int64(42)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := conn.ExecContext(ctx, tc.query, tc.args...); err != nil {
+				t.Fatal(err)
+			}
+
+			if outval, outmsg := readOutVars(t, conn); outval != 1 || outmsg == "" {
+				t.Fatalf("expected a failure, got %d: %q", outval, outmsg)
+			}
+		})
+	}
+
+	if rules := server.Rules(); len(rules) != 0 {
+		t.Fatalf("expected no rules, got %v", rules)
+	}
+}
+
+func TestMetadataTable(t *testing.T) {
+	ctx := context.Background()
+	server := New()
+	pool := server.DB()
+
+	_, err := pool.ExecContext(ctx, "DELETE FROM tf_audit_rule_metadata WHERE rule_id = ?", int64(1))
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != 1146 {
+		t.Fatalf("expected a missing table error, got %v", err)
+	}
+
+	if _, err := pool.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS `tf_audit_rule_metadata` (`rule_id` bigint NOT NULL)"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = pool.ExecContext(ctx, `INSERT INTO tf_audit_rule_metadata (rule_id, description, owner, ticket)
+VALUES (?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+	description = VALUES(description),
+	owner = VALUES(owner),
+	ticket = VALUES(ticket)`, int64(1), "description", sql.NullString{}, "TICKET-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, ok := server.Metadata(1)
+	if !ok || m.Description == nil || *m.Description != "description" || m.Owner != nil || m.Ticket == nil || *m.Ticket != "TICKET-1" {
+		t.Fatalf("unexpected metadata %+v", m)
+	}
+}
+
+func TestDenyCreateTable(t *testing.T) {
+	server := New()
+	server.DenyCreateTable = true
+
+	_, err := server.DB().Exec("CREATE TABLE IF NOT EXISTS `tf_audit_rule_metadata` (`rule_id` bigint NOT NULL)")
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != 1142 {
+		t.Fatalf("expected an access denied error, got %v", err)
+	}
+}
+
+func TestUnsupportedStatement(t *testing.T) {
+	if _, err := New().DB().Exec("DROP TABLE audit_log_rules"); err == nil {
+		t.Fatal("expected unsupported statements to fail")
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// guardrailsAttributes maps the guardrails provider block.
+type guardrailsAttributes struct {
+	MaxWildcards         types.Int64 `tfsdk:"max_wildcards"`
+	DisallowedOperations types.Set   `tfsdk:"disallowed_operations"`
+	BroadRuleWildcards   types.Int64 `tfsdk:"broad_rule_wildcards"`
+	BroadRuleOpResults   types.Set   `tfsdk:"broad_rule_op_results"`
+	AllowedUsers         types.List  `tfsdk:"allowed_users"`
+	DeniedUsers          types.List  `tfsdk:"denied_users"`
+}
+
+// guardedRuleFields are the rule fields that count towards the wildcard
+// breadth of a rule.
+var guardedRuleFields = []string{"username", "dbname", "object", "operation"}
+
+// auditGuardrails restrict the audit rules that can be planned, a negative
+// limit isn't enforced.
+type auditGuardrails struct {
+	maxWildcards         int64
+	disallowedOperations []string
+	broadRuleWildcards   int64
+	broadRuleOpResults   []string
+	allowedUsers         []string
+	deniedUsers          []string
+}
+
+// guardrailViolation is a rule field that breaks one of the guardrails.
+type guardrailViolation struct {
+	attribute string
+	message   string
+}
+
+// guardrailsFromAttributes validates the guardrails block, a null block
+// returns nil guardrails.
+func guardrailsFromAttributes(ctx context.Context, object types.Object) (*auditGuardrails, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	root := path.Root("guardrails")
+
+	if object.IsNull() {
+		return nil, diags
+	}
+	if object.IsUnknown() {
+		diags.AddAttributeError(
+			root,
+			"Unknown guardrails",
+			"The guardrails must be known when the provider is configured",
+		)
+		return nil, diags
+	}
+
+	var data guardrailsAttributes
+	diags.Append(object.As(ctx, &data, basetypes.ObjectAsOptions{})...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	guardrails := &auditGuardrails{maxWildcards: -1, broadRuleWildcards: -1}
+
+	for _, attribute := range []struct {
+		name  string
+		value types.Int64
+		min   int64
+		limit *int64
+	}{
+		{"max_wildcards", data.MaxWildcards, 0, &guardrails.maxWildcards},
+		{"broad_rule_wildcards", data.BroadRuleWildcards, 1, &guardrails.broadRuleWildcards},
+	} {
+		if attribute.value.IsNull() || attribute.value.IsUnknown() {
+			continue
+		}
+
+		value := attribute.value.ValueInt64()
+		if value < attribute.min || value > int64(len(guardedRuleFields)) {
+			diags.AddAttributeError(
+				root.AtName(attribute.name),
+				"Invalid guardrail",
+				fmt.Sprintf("%s must be between %d and %d, got %d", attribute.name, attribute.min, len(guardedRuleFields), value),
+			)
+			continue
+		}
+		*attribute.limit = value
+	}
+
+	diags.Append(data.DisallowedOperations.ElementsAs(ctx, &guardrails.disallowedOperations, false)...)
+	diags.Append(data.BroadRuleOpResults.ElementsAs(ctx, &guardrails.broadRuleOpResults, false)...)
+	diags.Append(data.AllowedUsers.ElementsAs(ctx, &guardrails.allowedUsers, false)...)
+	diags.Append(data.DeniedUsers.ElementsAs(ctx, &guardrails.deniedUsers, false)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	for i, opResult := range guardrails.broadRuleOpResults {
+		guardrails.broadRuleOpResults[i] = strings.ToUpper(opResult)
+	}
+
+	slices.Sort(guardrails.disallowedOperations)
+	slices.Sort(guardrails.broadRuleOpResults)
+
+	for _, opResult := range guardrails.broadRuleOpResults {
+		if !slices.Contains([]string{"S", "U", "B", "E"}, opResult) {
+			diags.AddAttributeError(
+				root.AtName("broad_rule_op_results"),
+				"Invalid guardrail",
+				fmt.Sprintf("Invalid op_result %q, allowed values: S, U, B, E", opResult),
+			)
+		}
+	}
+
+	if guardrails.broadRuleWildcards >= 0 && len(guardrails.broadRuleOpResults) == 0 {
+		diags.AddAttributeError(
+			root.AtName("broad_rule_op_results"),
+			"Invalid guardrail",
+			"broad_rule_op_results must be set together with broad_rule_wildcards",
+		)
+	} else if guardrails.broadRuleWildcards < 0 && len(guardrails.broadRuleOpResults) > 0 {
+		diags.AddAttributeError(
+			root.AtName("broad_rule_wildcards"),
+			"Invalid guardrail",
+			"broad_rule_wildcards must be set together with broad_rule_op_results",
+		)
+	}
+
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return guardrails, diags
+}
+
+// wildcardFields returns the fields of the rule that match every value, a
+// username matches every account when its user part is a wildcard.
+func wildcardFields(rule AuditRule) []string {
+	var fields []string
+	for _, field := range guardedRuleFields {
+		value := map[string]string{
+			"username":  rule.Username,
+			"dbname":    rule.Dbname,
+			"object":    rule.Object,
+			"operation": rule.Operation,
+		}[field]
+
+		entries := splitRuleList(value)
+		if len(entries) == 0 {
+			entries = []string{""}
+		}
+
+		if slices.ContainsFunc(entries, func(entry string) bool {
+			if field == "username" {
+				user, _ := splitRuleAccount(entry)
+				return isWildcard(entry) || isWildcard(user)
+			}
+			return isWildcard(entry)
+		}) {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// accountPatternsOverlap reports whether some account is covered by both
+// username patterns, the user and host parts overlap when one of them
+// matches the other.
+func accountPatternsOverlap(a, b string) bool {
+	if isWildcard(a) || isWildcard(b) {
+		return true
+	}
+
+	aUser, aHost := splitRuleAccount(a)
+	bUser, bHost := splitRuleAccount(b)
+
+	overlap := func(x, y string) bool {
+		return isWildcard(x) || isWildcard(y) || wildcardMatch(x, y) || wildcardMatch(y, x)
+	}
+
+	return overlap(aUser, bUser) && overlap(aHost, bHost)
+}
+
+// check returns the guardrails broken by the rule. Exclusion rules only
+// remove events from the audit log, so only the user lists apply to them.
+func (g *auditGuardrails) check(rule AuditRule) []guardrailViolation {
+	if g == nil {
+		return nil
+	}
+
+	opResult := strings.ToUpper(rule.OpResult)
+
+	var violations []guardrailViolation
+
+	for _, entry := range splitRuleList(rule.Username) {
+		if len(g.allowedUsers) > 0 && !slices.ContainsFunc(g.allowedUsers, func(pattern string) bool {
+			return wildcardMatch(pattern, entry)
+		}) {
+			violations = append(violations, guardrailViolation{
+				attribute: "username",
+				message:   fmt.Sprintf("Username %q is not in the allowed users: %s", entry, strings.Join(g.allowedUsers, ", ")),
+			})
+		}
+
+		// a username pattern covers the denied users it overlaps with
+		for _, pattern := range g.deniedUsers {
+			if accountPatternsOverlap(entry, pattern) {
+				violations = append(violations, guardrailViolation{
+					attribute: "username",
+					message:   fmt.Sprintf("Username %q matches the denied user %q", entry, pattern),
+				})
+			}
+		}
+	}
+
+	if opResult == "E" {
+		return violations
+	}
+
+	wildcards := wildcardFields(rule)
+
+	if g.maxWildcards >= 0 && int64(len(wildcards)) > g.maxWildcards {
+		violations = append(violations, guardrailViolation{
+			attribute: wildcards[len(wildcards)-1],
+			message: fmt.Sprintf("The rule matches every value of %s, at most %d wildcard fields are allowed",
+				strings.Join(wildcards, ", "), g.maxWildcards),
+		})
+	}
+
+	if g.broadRuleWildcards >= 0 && int64(len(wildcards)) >= g.broadRuleWildcards && !slices.Contains(g.broadRuleOpResults, opResult) {
+		violations = append(violations, guardrailViolation{
+			attribute: "op_result",
+			message: fmt.Sprintf("The rule matches every value of %s, rules with %d or more wildcard fields must use op_result %s, got %q",
+				strings.Join(wildcards, ", "), g.broadRuleWildcards, strings.Join(g.broadRuleOpResults, " or "), rule.OpResult),
+		})
+	}
+
+	operations := expandOperations(rule.Operation)
+	if len(operations) == 0 {
+		operations = []string{"*"}
+	}
+	for _, disallowed := range expandOperations(strings.Join(g.disallowedOperations, ",")) {
+		for _, operation := range operations {
+			if isWildcard(operation) || wildcardMatch(operation, disallowed) {
+				violations = append(violations, guardrailViolation{
+					attribute: "operation",
+					message:   fmt.Sprintf("Operation %q covers the disallowed operation %q", operation, disallowed),
+				})
+				break
+			}
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,259 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"terraform-provider-cloudsql-auditlog/internal/cloudsqlfake"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAuditGuardrailsCheck(t *testing.T) {
+	guardrails := &auditGuardrails{
+		maxWildcards:         3,
+		disallowedOperations: []string{"dql"},
+		broadRuleWildcards:   2,
+		broadRuleOpResults:   []string{"U", "E"},
+		deniedUsers:          []string{"root@*"},
+	}
+
+	tests := []struct {
+		name       string
+		guardrails *auditGuardrails
+		rule       AuditRule
+		want       []string
+	}{
+		{
+			name: "narrow rule",
+			rule: AuditRule{Username: "app@%", Dbname: "shop", Object: "shop.orders", Operation: "dml", OpResult: "B"},
+		},
+		{
+			name: "every field",
+			rule: AuditRule{Username: "*", Dbname: "*", Object: "*", Operation: "*", OpResult: "B"},
+			want: []string{"username", "operation", "op_result", "operation"},
+		},
+		{
+			name: "broad failures",
+			rule: AuditRule{Username: "app*@%", Dbname: "*", Object: "*", Operation: "ddl", OpResult: "U"},
+		},
+		{
+			name: "lower case op_result",
+			rule: AuditRule{Username: "app@%", Dbname: "*", Object: "*", Operation: "ddl", OpResult: "u"},
+		},
+		{
+			name: "broad successes",
+			rule: AuditRule{Username: "app@%", Dbname: "*", Object: "*", Operation: "ddl", OpResult: "S"},
+			want: []string{"op_result"},
+		},
+		{
+			name: "disallowed operation",
+			rule: AuditRule{Username: "app@%", Dbname: "shop", Object: "*", Operation: "sel*,insert", OpResult: "B"},
+			want: []string{"operation"},
+		},
+		{
+			name: "denied users",
+			rule: AuditRule{Username: "app@%,root@localhost", Dbname: "shop", Object: "shop.orders", Operation: "dml", OpResult: "B"},
+			want: []string{"username"},
+		},
+		{
+			name: "wildcard users",
+			rule: AuditRule{Username: "*@%", Dbname: "shop", Object: "shop.orders", Operation: "dml", OpResult: "B"},
+			want: []string{"username"},
+		},
+		{
+			name:       "allowed users",
+			guardrails: &auditGuardrails{maxWildcards: -1, broadRuleWildcards: -1, allowedUsers: []string{"app*@%"}},
+			rule:       AuditRule{Username: "app@%,appreport@%,report@%", Dbname: "*", Object: "*", Operation: "*", OpResult: "B"},
+			want:       []string{"username"},
+		},
+		{
+			name: "exclusion",
+			rule: AuditRule{Username: "app@%", Dbname: "*", Object: "*", Operation: "*", OpResult: "e"},
+		},
+		{
+			name: "wildcard exclusion",
+			rule: AuditRule{Username: "*", Dbname: "*", Object: "*", Operation: "*", OpResult: "E"},
+			want: []string{"username"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := guardrails
+			if tt.guardrails != nil {
+				g = tt.guardrails
+			}
+
+			var got []string
+			for _, violation := range g.check(tt.rule) {
+				got = append(got, violation.attribute)
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected violations of %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAccAuditLogRuleResourceGuardrails(t *testing.T) {
+	server, providerConfig := newTestInstance(t)
+	providerConfig = withGuardrails(providerConfig, `
+  guardrails = {
+    broad_rule_wildcards  = 3
+    broad_rule_op_results = ["U", "E"]
+    denied_users          = ["root@*"]
+  }
+`)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckNoAuditRules(server),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_log_rule" "test" {
+  username  = "*"
+  dbname    = "*"
+  object    = "*"
+  operation = "*"
+  op_result = "B"
+}
+`,
+				ExpectError: regexp.MustCompile(`wildcard\s+fields\s+must\s+use\s+op_result\s+E\s+or\s+U,\s+got\s+"B"`),
+			},
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_log_rule" "test" {
+  username  = "root@localhost"
+  dbname    = "*"
+  object    = "*"
+  operation = "ddl"
+  op_result = "B"
+}
+`,
+				ExpectError: regexp.MustCompile(`Username\s+"root@localhost"\s+matches\s+the\s+denied\s+user\s+"root@\*"`),
+			},
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_log_rule" "test" {
+  username  = "app*@%"
+  dbname    = "*"
+  object    = "*"
+  operation = "*"
+  op_result = "U"
+}
+`,
+				Check: testAccCheckActiveAuditRule(server, cloudsqlfake.Rule{
+					ID:        1,
+					Username:  "app*@%",
+					Dbname:    "*",
+					Object:    "*",
+					Operation: "*",
+					OpResult:  "U",
+				}),
+			},
+		},
+	})
+}
+
+func TestAccAuditPolicyResourceGuardrails(t *testing.T) {
+	server, providerConfig := newTestInstance(t)
+	providerConfig = withGuardrails(providerConfig, `
+  guardrails = {
+    disallowed_operations = ["dql"]
+  }
+`)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckNoAuditRules(server),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_policy" "test" {
+  name = "compliance"
+  statements = [
+    { actions = ["ddl"] },
+    { principal = "app@%", database = "billing", actions = ["read"] },
+  ]
+}
+`,
+				ExpectError: regexp.MustCompile(`Compiled\s+rule\s+1:\s+Operation\s+"select"\s+covers\s+the\s+disallowed\s+operation`),
+			},
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_policy" "test" {
+  name = "compliance"
+  statements = [
+    { actions = ["ddl"] },
+  ]
+}
+`,
+				Check: testAccCheckRuleCount(server, 1),
+			},
+		},
+	})
+}
+
+func TestAccAuditLogBaselineResourceGuardrails(t *testing.T) {
+	server, providerConfig := newTestInstance(t)
+	providerConfig = withGuardrails(providerConfig, `
+  guardrails = {
+    denied_users = ["root@*"]
+  }
+`)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckNoAuditRules(server),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_log_baseline" "test" {
+  preset              = "soc2"
+  privileged_accounts = ["root@%", "admin@%"]
+}
+`,
+				ExpectError: regexp.MustCompile(`Rule\s+privileged_access:\s+Username\s+"root@%"\s+matches\s+the\s+denied\s+user`),
+			},
+		},
+	})
+}
+
+func TestAccProviderGuardrailsInvalid(t *testing.T) {
+	_, providerConfig := newTestInstance(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: withGuardrails(providerConfig, `
+  guardrails = {
+    broad_rule_wildcards = 2
+  }
+`) + `
+resource "cloudsql-auditlog_audit_log_rule" "test" {
+  username  = "app@%"
+  dbname    = "shop"
+  object    = "*"
+  operation = "ddl"
+  op_result = "B"
+}
+`,
+				ExpectError: regexp.MustCompile(`broad_rule_op_results\s+must\s+be\s+set\s+together\s+with\s+broad_rule_wildcards`),
+			},
+		},
+	})
+}
+
+// withGuardrails adds the guardrails to the provider block of the test
+// configuration.
+func withGuardrails(providerConfig, guardrails string) string {
+	return strings.TrimSuffix(strings.TrimSpace(providerConfig), "}") + strings.TrimPrefix(guardrails, "\n") + "}\n"
+}
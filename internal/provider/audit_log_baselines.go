@@ -0,0 +1,130 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"slices"
+	"strings"
+)
+
+// baselineRule is a named rule of a baseline preset, the name is what
+// exclusions refer to. The privileged flag replaces the username with the
+// privileged accounts of the baseline.
+type baselineRule struct {
+	name       string
+	privileged bool
+	rule       AuditRule
+}
+
+// baselineVersion is a released version of a preset, versions are never
+// changed once released so that pinned baselines keep their rules.
+type baselineVersion struct {
+	version string
+	rules   []baselineRule
+}
+
+// Rules shared by the presets.
+var (
+	baselineDDLDCL = baselineRule{
+		name: "ddl_dcl",
+		rule: AuditRule{Username: "*", Dbname: "*", Object: "*", Operation: "ddl,dcl", OpResult: "B"},
+	}
+	baselineFailedLogins = baselineRule{
+		name: "failed_logins",
+		rule: AuditRule{Username: "*", Dbname: "*", Object: "*", Operation: "connect", OpResult: "U"},
+	}
+	baselinePrivilegedAccess = baselineRule{
+		name:       "privileged_access",
+		privileged: true,
+		rule:       AuditRule{Dbname: "*", Object: "*", Operation: "*", OpResult: "B"},
+	}
+	baselineFailedStatements = baselineRule{
+		name: "failed_statements",
+		rule: AuditRule{Username: "*", Dbname: "*", Object: "*", Operation: "*", OpResult: "U"},
+	}
+	baselineDataChanges = baselineRule{
+		name: "data_changes",
+		rule: AuditRule{Username: "*", Dbname: "*", Object: "*", Operation: "dml", OpResult: "B"},
+	}
+)
+
+// baselinePresets maps the preset names to their versions, oldest first.
+var baselinePresets = map[string][]baselineVersion{
+	"pci-dss": {
+		{version: "1", rules: []baselineRule{baselineDDLDCL, baselineFailedLogins, baselinePrivilegedAccess, baselineFailedStatements}},
+	},
+	"soc2": {
+		{version: "1", rules: []baselineRule{baselineDDLDCL, baselineFailedLogins, baselinePrivilegedAccess}},
+	},
+	"hipaa": {
+		{version: "1", rules: []baselineRule{baselineDDLDCL, baselineFailedLogins, baselinePrivilegedAccess, baselineDataChanges}},
+	},
+}
+
+// defaultPrivilegedAccounts are audited by the privileged_access rules when
+// the baseline doesn't list the privileged accounts.
+var defaultPrivilegedAccounts = []string{"root@%"}
+
+// baselinePresetNames returns the preset names sorted.
+func baselinePresetNames() []string {
+	names := make([]string, 0, len(baselinePresets))
+	for name := range baselinePresets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// baselinePreset returns a version of a preset, an empty version is the
+// latest one.
+func baselinePreset(name, version string) (baselineVersion, bool) {
+	versions, ok := baselinePresets[name]
+	if !ok || len(versions) == 0 {
+		return baselineVersion{}, false
+	}
+
+	if version == "" {
+		return versions[len(versions)-1], true
+	}
+
+	i := slices.IndexFunc(versions, func(v baselineVersion) bool { return v.version == version })
+	if i < 0 {
+		return baselineVersion{}, false
+	}
+
+	return versions[i], true
+}
+
+// ruleNames returns the names of the rules of the preset version.
+func (v baselineVersion) ruleNames() []string {
+	names := make([]string, 0, len(v.rules))
+	for _, rule := range v.rules {
+		names = append(names, rule.name)
+	}
+
+	return names
+}
+
+// expand returns the concrete rules of the preset version without the
+// excluded ones, keyed by name in the preset order.
+func (v baselineVersion) expand(exclusions, privilegedAccounts []string) []baselineRule {
+	if len(privilegedAccounts) == 0 {
+		privilegedAccounts = defaultPrivilegedAccounts
+	}
+
+	var rules []baselineRule
+	for _, rule := range v.rules {
+		if slices.Contains(exclusions, rule.name) {
+			continue
+		}
+
+		if rule.privileged {
+			rule.rule.Username = strings.Join(privilegedAccounts, ",")
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
@@ -0,0 +1,260 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// policyAction maps an engine neutral action class to what enables auditing
+// it on each engine.
+type policyAction struct {
+	// operation is the audit rule operation on mysql.
+	operation string
+
+	// pgauditClass is the pgaudit.log statement class.
+	pgauditClass string
+
+	// privileges are the privileges granted to the pgaudit.role role to
+	// audit the action on a single table, object auditing isn't possible
+	// without any.
+	privileges []string
+}
+
+// policyActions are the action classes of the audit policy statements.
+var policyActions = map[string]policyAction{
+	"read":  {operation: "dql", pgauditClass: "read", privileges: []string{"SELECT"}},
+	"write": {operation: "dml", pgauditClass: "write", privileges: []string{"INSERT", "UPDATE", "DELETE"}},
+	"ddl":   {operation: "ddl", pgauditClass: "ddl"},
+	"dcl":   {operation: "dcl", pgauditClass: "role"},
+	"all":   {operation: "*", pgauditClass: "all", privileges: []string{"SELECT", "INSERT", "UPDATE", "DELETE"}},
+}
+
+// policyActionOrder is the order of the classes in the compiled operations.
+var policyActionOrder = []string{"read", "write", "ddl", "dcl", "all"}
+
+// policyOutcomes maps the statement outcomes to the audit rule op_result.
+var policyOutcomes = map[string]string{
+	"success": "S",
+	"failure": "U",
+	"any":     "B",
+}
+
+// Kinds of the backend objects an audit policy compiles to.
+const (
+	compiledAuditLogRule       = "audit_log_rule"
+	compiledPgauditSetting     = "pgaudit_setting"
+	compiledPgauditObjectAudit = "pgaudit_object_audit"
+)
+
+// policyStatement is an engine neutral audit policy statement, "*" matches
+// every principal, database or object.
+type policyStatement struct {
+	Principal string
+	Database  string
+	Object    string
+	Actions   []string
+	Outcome   string
+}
+
+// compiledObject is a backend object generated from an audit policy, in the
+// fields of the mysql audit rules. For pgaudit settings the operation is the
+// pgaudit.log value and for object audits the granted privileges.
+type compiledObject struct {
+	Kind      string
+	Username  string
+	Dbname    string
+	Object    string
+	Operation string
+	OpResult  string
+}
+
+// compilePolicy translates the statements into the backend objects of the
+// engine, statements that can't be expressed on the engine return an error.
+func compilePolicy(engine string, statements []policyStatement) ([]compiledObject, error) {
+	for i, statement := range statements {
+		if len(statement.Actions) == 0 {
+			return nil, fmt.Errorf("statement %d: at least one action is required", i)
+		}
+
+		for _, action := range statement.Actions {
+			if _, ok := policyActions[action]; !ok {
+				return nil, fmt.Errorf("statement %d: invalid action %q, allowed values: %s", i, action, strings.Join(policyActionOrder, ", "))
+			}
+		}
+
+		if _, ok := policyOutcomes[statement.Outcome]; !ok {
+			return nil, fmt.Errorf("statement %d: invalid outcome %q, allowed values: success, failure, any", i, statement.Outcome)
+		}
+	}
+
+	switch engine {
+	case "mysql":
+		return compileMySQLPolicy(statements), nil
+	case "postgresql":
+		return compilePostgreSQLPolicy(statements)
+	}
+
+	return nil, fmt.Errorf("audit policies are not supported by the %q engine", engine)
+}
+
+// compileMySQLPolicy returns an audit rule per statement, identical rules
+// are only created once.
+func compileMySQLPolicy(statements []policyStatement) []compiledObject {
+	var compiled []compiledObject
+	for _, statement := range statements {
+		var operations []string
+		for _, action := range orderedActions(statement.Actions) {
+			operations = append(operations, policyActions[action].operation)
+		}
+
+		rule := compiledObject{
+			Kind:      compiledAuditLogRule,
+			Username:  statement.Principal,
+			Dbname:    statement.Database,
+			Object:    statement.Object,
+			Operation: strings.Join(operations, ","),
+			OpResult:  policyOutcomes[statement.Outcome],
+		}
+		if !slices.Contains(compiled, rule) {
+			compiled = append(compiled, rule)
+		}
+	}
+
+	return compiled
+}
+
+// compilePostgreSQLPolicy returns a pgaudit.log setting per role and
+// database scope for the statements on every object, and the grants to the
+// pgaudit.role role for the statements on a single table.
+func compilePostgreSQLPolicy(statements []policyStatement) ([]compiledObject, error) {
+	var settings, grants []compiledObject
+	settingClasses := make(map[[2]string][]string)
+	grantPrivileges := make(map[[2]string][]string)
+
+	for i, statement := range statements {
+		// pgaudit logs the statements when they are executed, failures
+		// before that are only in the postgresql error log
+		if statement.Outcome == "failure" {
+			return nil, fmt.Errorf("statement %d: pgaudit only logs the statements that are executed, use outcome success or any", i)
+		}
+
+		if isWildcard(statement.Principal) {
+			statement.Principal = "*"
+		}
+		if isWildcard(statement.Database) {
+			statement.Database = "*"
+		}
+
+		if isWildcard(statement.Object) {
+			if statement.Principal == "*" && statement.Database == "*" {
+				return nil, fmt.Errorf("statement %d: pgaudit.log can't be set for every role and database, set the pgaudit.log database flag instead or limit the principal or database", i)
+			}
+
+			scope := [2]string{statement.Principal, statement.Database}
+			if _, ok := settingClasses[scope]; !ok {
+				settings = append(settings, compiledObject{
+					Kind:     compiledPgauditSetting,
+					Username: statement.Principal,
+					Dbname:   statement.Database,
+					Object:   "*",
+					OpResult: "S",
+				})
+			}
+			settingClasses[scope] = append(settingClasses[scope], statement.Actions...)
+
+			continue
+		}
+
+		if statement.Principal != "*" {
+			return nil, fmt.Errorf("statement %d: object audit logging applies to every role, the principal must be \"*\"", i)
+		} else if statement.Database == "*" {
+			return nil, fmt.Errorf("statement %d: object audit logging is set up per database, the database can't be \"*\"", i)
+		} else if hasWildcard(statement.Object) || strings.Contains(statement.Object, ",") {
+			return nil, fmt.Errorf("statement %d: object audit logging needs a single table, got %q", i, statement.Object)
+		}
+
+		object := statement.Object
+		if !strings.Contains(object, ".") {
+			object = "public." + object
+		}
+
+		target := [2]string{statement.Database, object}
+		if _, ok := grantPrivileges[target]; !ok {
+			grants = append(grants, compiledObject{
+				Kind:     compiledPgauditObjectAudit,
+				Username: "*",
+				Dbname:   statement.Database,
+				Object:   object,
+				OpResult: "S",
+			})
+		}
+		for _, action := range statement.Actions {
+			if len(policyActions[action].privileges) == 0 {
+				return nil, fmt.Errorf("statement %d: %s statements can't be audited on a single table, use object \"*\"", i, action)
+			}
+			grantPrivileges[target] = append(grantPrivileges[target], policyActions[action].privileges...)
+		}
+	}
+
+	for i, setting := range settings {
+		var classes []string
+		for _, action := range orderedActions(settingClasses[[2]string{setting.Username, setting.Dbname}]) {
+			classes = append(classes, policyActions[action].pgauditClass)
+		}
+		settings[i].Operation = strings.Join(classes, ",")
+	}
+
+	for i, grant := range grants {
+		granted := grantPrivileges[[2]string{grant.Dbname, grant.Object}]
+
+		var privileges []string
+		for _, privilege := range policyActions["all"].privileges {
+			if slices.Contains(granted, privilege) {
+				privileges = append(privileges, strings.ToLower(privilege))
+			}
+		}
+		grants[i].Operation = strings.Join(privileges, ",")
+	}
+
+	return append(settings, grants...), nil
+}
+
+// orderedActions returns the distinct actions in policyActionOrder, all
+// covers every other action.
+func orderedActions(actions []string) []string {
+	if slices.Contains(actions, "all") {
+		return []string{"all"}
+	}
+
+	var ordered []string
+	for _, action := range policyActionOrder {
+		if slices.Contains(actions, action) {
+			ordered = append(ordered, action)
+		}
+	}
+
+	return ordered
+}
+
+// pgauditScope returns the role and database of a compiled pgaudit setting,
+// empty for "*".
+func (o compiledObject) pgauditScope() (string, string) {
+	role, database := o.Username, o.Dbname
+	if isWildcard(role) {
+		role = ""
+	}
+	if isWildcard(database) {
+		database = ""
+	}
+
+	return role, database
+}
+
+// grantPrivileges returns the privileges of a compiled object audit.
+func (o compiledObject) grantPrivileges() []string {
+	return strings.Split(strings.ToUpper(o.Operation), ",")
+}
@@ -0,0 +1,738 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lib/pq"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource               = &auditPolicyResource{}
+	_ resource.ResourceWithConfigure  = &auditPolicyResource{}
+	_ resource.ResourceWithModifyPlan = &auditPolicyResource{}
+)
+
+func NewAuditPolicyResource() resource.Resource {
+	return &auditPolicyResource{}
+}
+
+// auditPolicyResource manages an engine neutral audit policy, compiled into
+// audit rules on mysql and pgaudit settings and grants on postgresql.
+type auditPolicyResource struct {
+	client CloudSqlClientAndConfig
+}
+
+type auditPolicyResourceModel struct {
+	ID         types.String `tfsdk:"id"`
+	Instance   types.String `tfsdk:"instance"`
+	Name       types.String `tfsdk:"name"`
+	Statements types.List   `tfsdk:"statements"`
+	Compiled   types.List   `tfsdk:"compiled"`
+}
+
+type auditPolicyStatementModel struct {
+	Principal types.String `tfsdk:"principal"`
+	Database  types.String `tfsdk:"database"`
+	Object    types.String `tfsdk:"object"`
+	Actions   types.Set    `tfsdk:"actions"`
+	Outcome   types.String `tfsdk:"outcome"`
+}
+
+type auditPolicyCompiledModel struct {
+	Kind      types.String `tfsdk:"kind"`
+	ID        types.String `tfsdk:"id"`
+	Username  types.String `tfsdk:"username"`
+	DbName    types.String `tfsdk:"dbname"`
+	Object    types.String `tfsdk:"object"`
+	Operation types.String `tfsdk:"operation"`
+	OpResult  types.String `tfsdk:"op_result"`
+}
+
+var auditPolicyCompiledType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"kind":      types.StringType,
+	"id":        types.StringType,
+	"username":  types.StringType,
+	"dbname":    types.StringType,
+	"object":    types.StringType,
+	"operation": types.StringType,
+	"op_result": types.StringType,
+}}
+
+// compiledEntry is a compiled object along with the id of the backend
+// object: the rule id on mysql, the pgaudit.role role the privileges were
+// granted to for object audits and the role/database scope for settings.
+type compiledEntry struct {
+	compiledObject
+	id string
+}
+
+func (r *auditPolicyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_audit_policy"
+}
+
+func (r *auditPolicyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"instance": schema.StringAttribute{
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"statements": schema.ListNestedAttribute{
+				Required: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"principal": schema.StringAttribute{
+							Optional: true,
+							Computed: true,
+							Default:  stringdefault.StaticString("*"),
+						},
+						"database": schema.StringAttribute{
+							Optional: true,
+							Computed: true,
+							Default:  stringdefault.StaticString("*"),
+						},
+						"object": schema.StringAttribute{
+							Optional: true,
+							Computed: true,
+							Default:  stringdefault.StaticString("*"),
+						},
+						"actions": schema.SetAttribute{
+							Required:    true,
+							ElementType: types.StringType,
+						},
+						"outcome": schema.StringAttribute{
+							Optional: true,
+							Computed: true,
+							Default:  stringdefault.StaticString("any"),
+						},
+					},
+				},
+			},
+			"compiled": schema.ListAttribute{
+				Computed:    true,
+				ElementType: auditPolicyCompiledType,
+			},
+		},
+	}
+}
+
+// ModifyPlan compiles the statements so that the plan shows the backend
+// objects, the ids of the objects that are kept come from the state.
+func (r *auditPolicyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to compile when the resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	// the engine is needed to compile the policy
+	if r.client.unknown || r.client.engine == "" {
+		return
+	}
+
+	var plan auditPolicyResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	statements, known, diags := plan.policyStatements(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || !known {
+		return
+	}
+
+	compiled, err := compilePolicy(r.client.engine, statements)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("statements"),
+			"Invalid audit policy",
+			err.Error(),
+		)
+		return
+	}
+
+	// the compiled rules are held to the same guardrails as the
+	// audit_log_rule resources
+	for i, object := range compiled {
+		if object.Kind != compiledAuditLogRule {
+			continue
+		}
+		for _, violation := range r.client.guardrails.check(object.auditRule()) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("statements"),
+				"Audit policy violates the provider guardrails",
+				fmt.Sprintf("Compiled rule %d: %s", i, violation.message),
+			)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var current []compiledEntry
+	if !req.State.Raw.IsNull() {
+		var state auditPolicyResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		current, diags = state.compiledEntries(ctx)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	var models []auditPolicyCompiledModel
+	for _, object := range compiled {
+		id := types.StringUnknown()
+		if i := slices.IndexFunc(current, func(e compiledEntry) bool { return e.compiledObject == object }); i >= 0 {
+			id = types.StringValue(current[i].id)
+		}
+		models = append(models, compiledModel(object, id))
+	}
+
+	plan.Compiled, diags = types.ListValueFrom(ctx, auditPolicyCompiledType, models)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.Plan.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *auditPolicyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan auditPolicyResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = plan.Name
+	if !plan.Instance.IsNull() {
+		plan.ID = types.StringValue(plan.Instance.ValueString() + "/" + plan.Name.ValueString())
+	}
+
+	resp.Diagnostics.Append(r.apply(ctx, &plan, nil)...)
+
+	// the objects created before an error are kept in the state
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *auditPolicyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state auditPolicyResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the policy can't be refreshed until the provider configuration is
+	// known, keep the current state until then
+	if r.client.unknown {
+		return
+	}
+
+	entries, diags := state.compiledEntries(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var refreshed []compiledEntry
+	for _, entry := range entries {
+		object, found, err := r.readObject(ctx, state.Instance, entry)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading audit policy",
+				fmt.Sprintf("Could not read %s %s: %s", entry.Kind, entry.id, err.Error()),
+			)
+			return
+		}
+
+		// objects removed outside of terraform are created again by the
+		// next apply, changed ones are replaced
+		if found {
+			refreshed = append(refreshed, compiledEntry{compiledObject: object, id: entry.id})
+		}
+	}
+
+	state.Compiled, diags = compiledValue(ctx, refreshed)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *auditPolicyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state auditPolicyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	current, diags := state.compiledEntries(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.apply(ctx, &plan, current)...)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *auditPolicyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state auditPolicyResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	current, diags := state.compiledEntries(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	remaining, diags := r.sync(ctx, state.Instance, current, nil)
+	resp.Diagnostics.Append(diags...)
+	if !resp.Diagnostics.HasError() {
+		return
+	}
+
+	// keep track of the objects that couldn't be removed
+	state.Compiled, diags = compiledValue(ctx, remaining)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
+
+// apply compiles the planned statements and brings the backend objects of
+// current in line with them, m.Compiled is set to the objects that exist
+// afterwards even when some of them couldn't be changed.
+func (r *auditPolicyResource) apply(ctx context.Context, m *auditPolicyResourceModel, current []compiledEntry) diag.Diagnostics {
+	statements, _, diags := m.policyStatements(ctx)
+
+	var compiled []compiledObject
+	if !diags.HasError() {
+		var err error
+		compiled, err = compilePolicy(r.client.engine, statements)
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("statements"),
+				"Invalid audit policy",
+				err.Error(),
+			)
+		}
+	}
+
+	entries := current
+	if !diags.HasError() {
+		var d diag.Diagnostics
+		entries, d = r.sync(ctx, m.Instance, current, compiled)
+		diags.Append(d...)
+	}
+
+	var d diag.Diagnostics
+	m.Compiled, d = compiledValue(ctx, entries)
+	diags.Append(d...)
+
+	return diags
+}
+
+// sync removes the objects of current that aren't compiled and creates the
+// missing ones, it returns the objects that exist afterwards in the order
+// they were compiled.
+func (r *auditPolicyResource) sync(ctx context.Context, instance types.String, current []compiledEntry, compiled []compiledObject) ([]compiledEntry, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var remaining []compiledEntry
+	for i, entry := range current {
+		if slices.Contains(compiled, entry.compiledObject) {
+			remaining = append(remaining, entry)
+			continue
+		}
+
+		if err := r.removeObject(ctx, instance, entry); err != nil {
+			diags.AddError(
+				fmt.Sprintf("Unable to remove %s", entry.Kind),
+				err.Error(),
+			)
+			return append(remaining, current[i:]...), diags
+		}
+	}
+
+	var entries []compiledEntry
+	for _, object := range compiled {
+		i := slices.IndexFunc(remaining, func(e compiledEntry) bool { return e.compiledObject == object })
+		if i >= 0 {
+			entries = append(entries, remaining[i])
+			continue
+		}
+
+		id, err := r.createObject(ctx, instance, object)
+		if err != nil {
+			diags.AddError(
+				fmt.Sprintf("Unable to create %s", object.Kind),
+				err.Error(),
+			)
+			return entries, diags
+		}
+		entries = append(entries, compiledEntry{compiledObject: object, id: id})
+	}
+
+	return entries, diags
+}
+
+// createObject creates a compiled object and returns its id.
+func (r *auditPolicyResource) createObject(ctx context.Context, instance types.String, object compiledObject) (string, error) {
+	switch object.Kind {
+	case compiledAuditLogRule:
+		store, release, err := r.client.auditRuleStore(ctx, instance)
+		if err != nil {
+			return "", err
+		}
+		defer release()
+
+		rule := object.auditRule()
+		if id, err := store.Find(ctx, rule); err == nil {
+			return "", fmt.Errorf("rule already exists, existing ID: %d", id)
+		} else if !errors.Is(err, ErrAuditRuleNotFound) {
+			return "", err
+		}
+
+		id, err := store.Create(ctx, rule)
+		return strconv.FormatInt(id, 10), err
+
+	case compiledPgauditSetting:
+		role, database := object.pgauditScope()
+		conn, err := r.client.postgreSQLDatabase(ctx, instance, database)
+		if err != nil {
+			return "", err
+		}
+
+		query := pgauditAlterStatement(role, database) + " SET pgaudit.log = " + pq.QuoteLiteral(object.Operation)
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			return "", err
+		}
+
+		return role + "/" + database, nil
+
+	case compiledPgauditObjectAudit:
+		conn, err := r.client.postgreSQLDatabase(ctx, instance, object.Dbname)
+		if err != nil {
+			return "", err
+		}
+
+		role, err := readPgauditObjectRole(ctx, conn)
+		if err != nil {
+			return "", err
+		} else if role == "" {
+			return "", fmt.Errorf("pgaudit.role is not set in database %q, object audit logging needs an auditor role", object.Dbname)
+		}
+
+		if diags := changeObjectGrants(ctx, conn, "GRANT", object.schema(), object.table(), role, object.objectGrants()); diags.HasError() {
+			return "", errors.New(diags[0].Detail())
+		}
+
+		return role, nil
+	}
+
+	return "", fmt.Errorf("unknown compiled object kind %q", object.Kind)
+}
+
+// removeObject removes a compiled object, objects that are already gone are
+// ignored.
+func (r *auditPolicyResource) removeObject(ctx context.Context, instance types.String, entry compiledEntry) error {
+	switch entry.Kind {
+	case compiledAuditLogRule:
+		store, release, err := r.client.auditRuleStore(ctx, instance)
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		id, err := strconv.ParseInt(entry.id, 10, 64)
+		if err != nil {
+			return err
+		}
+
+		if err := store.Delete(ctx, id); err != nil && !errors.Is(err, ErrAuditRuleNotFound) {
+			return err
+		}
+
+		return nil
+
+	case compiledPgauditSetting:
+		role, database := entry.pgauditScope()
+		conn, err := r.client.postgreSQLDatabase(ctx, instance, database)
+		if err != nil {
+			return err
+		}
+
+		_, err = conn.ExecContext(ctx, pgauditAlterStatement(role, database)+" RESET pgaudit.log")
+		return err
+
+	case compiledPgauditObjectAudit:
+		conn, err := r.client.postgreSQLDatabase(ctx, instance, entry.Dbname)
+		if err != nil {
+			return err
+		}
+
+		if diags := changeObjectGrants(ctx, conn, "REVOKE", entry.schema(), entry.table(), entry.id, entry.objectGrants()); diags.HasError() {
+			return errors.New(diags[0].Detail())
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("unknown compiled object kind %q", entry.Kind)
+}
+
+// readObject returns the compiled object as it currently exists, and
+// whether it exists at all.
+func (r *auditPolicyResource) readObject(ctx context.Context, instance types.String, entry compiledEntry) (compiledObject, bool, error) {
+	object := entry.compiledObject
+
+	switch entry.Kind {
+	case compiledAuditLogRule:
+		store, release, err := r.client.auditRuleStore(ctx, instance)
+		if err != nil {
+			return object, false, err
+		}
+		defer release()
+
+		id, err := strconv.ParseInt(entry.id, 10, 64)
+		if err != nil {
+			return object, false, err
+		}
+
+		rule, err := store.Get(ctx, id)
+		if errors.Is(err, ErrAuditRuleNotFound) {
+			return object, false, nil
+		} else if err != nil {
+			return object, false, err
+		}
+
+		object.Username = rule.Username
+		object.Dbname = rule.Dbname
+		object.Object = rule.Object
+		object.Operation = rule.Operation
+		object.OpResult = rule.OpResult
+
+		return object, true, nil
+
+	case compiledPgauditSetting:
+		role, database := entry.pgauditScope()
+		conn, err := r.client.postgreSQLDatabase(ctx, instance, database)
+		if err != nil {
+			return object, false, err
+		}
+
+		settings, err := readPgauditSettings(ctx, conn, role, database)
+		if err != nil {
+			return object, false, err
+		}
+
+		log, ok := settings["pgaudit.log"]
+		object.Operation = log
+
+		return object, ok, nil
+
+	case compiledPgauditObjectAudit:
+		conn, err := r.client.postgreSQLDatabase(ctx, instance, entry.Dbname)
+		if err != nil {
+			return object, false, err
+		}
+
+		grants, err := readObjectGrants(ctx, conn, entry.id, entry.schema(), entry.table())
+		if err != nil {
+			return object, false, err
+		}
+
+		// privileges granted by others on top of the compiled ones are left
+		// alone
+		var privileges []string
+		for _, privilege := range entry.grantPrivileges() {
+			if slices.Contains(grants, objectGrant{privilege: privilege}) {
+				privileges = append(privileges, strings.ToLower(privilege))
+			}
+		}
+		object.Operation = strings.Join(privileges, ",")
+
+		return object, len(privileges) > 0, nil
+	}
+
+	return object, false, fmt.Errorf("unknown compiled object kind %q", entry.Kind)
+}
+
+// policyStatements returns the statements of the model, known is false when
+// some of them are only known during apply.
+func (m auditPolicyResourceModel) policyStatements(ctx context.Context) ([]policyStatement, bool, diag.Diagnostics) {
+	if m.Statements.IsUnknown() || m.Statements.IsNull() {
+		return nil, false, nil
+	}
+
+	var models []auditPolicyStatementModel
+	diags := m.Statements.ElementsAs(ctx, &models, false)
+	if diags.HasError() {
+		return nil, false, diags
+	}
+
+	var statements []policyStatement
+	for _, s := range models {
+		if s.Principal.IsUnknown() || s.Database.IsUnknown() || s.Object.IsUnknown() || s.Actions.IsUnknown() || s.Outcome.IsUnknown() {
+			return nil, false, diags
+		}
+
+		statement := policyStatement{
+			Principal: s.Principal.ValueString(),
+			Database:  s.Database.ValueString(),
+			Object:    s.Object.ValueString(),
+			Outcome:   s.Outcome.ValueString(),
+		}
+		diags.Append(s.Actions.ElementsAs(ctx, &statement.Actions, false)...)
+
+		statements = append(statements, statement)
+	}
+
+	return statements, true, diags
+}
+
+// compiledEntries returns the compiled objects of the state.
+func (m auditPolicyResourceModel) compiledEntries(ctx context.Context) ([]compiledEntry, diag.Diagnostics) {
+	if m.Compiled.IsNull() || m.Compiled.IsUnknown() {
+		return nil, nil
+	}
+
+	var models []auditPolicyCompiledModel
+	diags := m.Compiled.ElementsAs(ctx, &models, false)
+
+	var entries []compiledEntry
+	for _, c := range models {
+		entries = append(entries, compiledEntry{
+			compiledObject: compiledObject{
+				Kind:      c.Kind.ValueString(),
+				Username:  c.Username.ValueString(),
+				Dbname:    c.DbName.ValueString(),
+				Object:    c.Object.ValueString(),
+				Operation: c.Operation.ValueString(),
+				OpResult:  c.OpResult.ValueString(),
+			},
+			id: c.ID.ValueString(),
+		})
+	}
+
+	return entries, diags
+}
+
+func compiledModel(object compiledObject, id types.String) auditPolicyCompiledModel {
+	return auditPolicyCompiledModel{
+		Kind:      types.StringValue(object.Kind),
+		ID:        id,
+		Username:  types.StringValue(object.Username),
+		DbName:    types.StringValue(object.Dbname),
+		Object:    types.StringValue(object.Object),
+		Operation: types.StringValue(object.Operation),
+		OpResult:  types.StringValue(object.OpResult),
+	}
+}
+
+func compiledValue(ctx context.Context, entries []compiledEntry) (types.List, diag.Diagnostics) {
+	models := []auditPolicyCompiledModel{}
+	for _, entry := range entries {
+		models = append(models, compiledModel(entry.compiledObject, types.StringValue(entry.id)))
+	}
+
+	return types.ListValueFrom(ctx, auditPolicyCompiledType, models)
+}
+
+// auditRule returns the audit rule of a compiled mysql rule.
+func (o compiledObject) auditRule() AuditRule {
+	return AuditRule{
+		Username:  o.Username,
+		Dbname:    o.Dbname,
+		Object:    o.Object,
+		Operation: o.Operation,
+		OpResult:  o.OpResult,
+	}
+}
+
+func (o compiledObject) schema() string {
+	schema, _, _ := strings.Cut(o.Object, ".")
+	return schema
+}
+
+func (o compiledObject) table() string {
+	_, table, _ := strings.Cut(o.Object, ".")
+	return table
+}
+
+// objectGrants returns the table privileges of a compiled object audit.
+func (o compiledObject) objectGrants() []objectGrant {
+	var grants []objectGrant
+	for _, privilege := range o.grantPrivileges() {
+		grants = append(grants, objectGrant{privilege: privilege})
+	}
+
+	return grants
+}
+
+func (r *auditPolicyResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(CloudSqlClientAndConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *sql.DB got %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
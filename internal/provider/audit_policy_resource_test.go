@@ -0,0 +1,154 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"terraform-provider-cloudsql-auditlog/internal/cloudsqlfake"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccAuditPolicyResourceMySQL(t *testing.T) {
+	server, providerConfig := newTestInstance(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckNoAuditRules(server),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_policy" "test" {
+  name = "compliance"
+  statements = [
+    { actions = ["ddl"] },
+    { principal = "app@%", database = "billing", actions = ["write"], outcome = "success" },
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_policy.test", "id", "compliance"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_policy.test", "compiled.#", "2"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_policy.test", "compiled.0.kind", "audit_log_rule"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_policy.test", "compiled.0.id", "1"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_policy.test", "compiled.0.operation", "ddl"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_policy.test", "compiled.0.op_result", "B"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_policy.test", "compiled.1.username", "app@%"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_policy.test", "compiled.1.operation", "dml"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_policy.test", "compiled.1.op_result", "S"),
+					testAccCheckRuleCount(server, 2),
+				),
+			},
+			{
+				// only the changed statement is replaced
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_policy" "test" {
+  name = "compliance"
+  statements = [
+    { actions = ["ddl"] },
+    { principal = "app@%", database = "billing", actions = ["read", "write"], outcome = "success" },
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_policy.test", "compiled.0.id", "1"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_policy.test", "compiled.1.id", "3"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_policy.test", "compiled.1.operation", "dql,dml"),
+					testAccCheckRuleCount(server, 2),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAuditPolicyResourcePostgreSQL(t *testing.T) {
+	server, providerConfig := newTestPostgresInstance(t)
+	server.SetSetting(cloudsqlfake.SettingScope{Database: "app"}, "pgaudit.role", "auditor")
+	scope := cloudsqlfake.SettingScope{Database: "app"}
+
+	config := providerConfig + `
+resource "cloudsql-auditlog_audit_policy" "test" {
+  name = "compliance"
+  statements = [
+    { database = "app", actions = ["ddl", "dcl"] },
+    { database = "app", object = "orders", actions = ["write"] },
+  ]
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckObjectGrants(server, ""),
+			testAccCheckPgauditSettings(server, scope, map[string]string{"pgaudit.role": "auditor"}),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_policy.test", "compiled.#", "2"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_policy.test", "compiled.0.kind", "pgaudit_setting"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_policy.test", "compiled.0.operation", "ddl,role"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_policy.test", "compiled.1.kind", "pgaudit_object_audit"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_policy.test", "compiled.1.id", "auditor"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_policy.test", "compiled.1.object", "public.orders"),
+					testAccCheckPgauditSettings(server, scope, map[string]string{"pgaudit.role": "auditor", "pgaudit.log": "ddl,role"}),
+					testAccCheckObjectGrants(server, "DELETE INSERT UPDATE"),
+				),
+			},
+			{
+				// grants revoked outside of terraform are granted again
+				PreConfig: func() {
+					server.RevokeGrant(cloudsqlfake.Grant{
+						Database: "app", Schema: "public", Table: "orders", Role: "auditor", Privilege: "DELETE",
+					})
+				},
+				Config: config,
+				Check:  testAccCheckObjectGrants(server, "DELETE INSERT UPDATE"),
+			},
+		},
+	})
+}
+
+func TestAccAuditPolicyResourceInvalid(t *testing.T) {
+	_, providerConfig := newTestPostgresInstance(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_policy" "test" {
+  name       = "compliance"
+  statements = [{ actions = ["ddl"], outcome = "failure", database = "app" }]
+}
+`,
+				ExpectError: regexp.MustCompile(`pgaudit only logs the statements that are executed`),
+			},
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_policy" "test" {
+  name       = "compliance"
+  statements = [{ actions = ["ddl"], database = "app", object = "orders" }]
+}
+`,
+				ExpectError: regexp.MustCompile(`ddl statements can't be audited on a single table`),
+			},
+		},
+	})
+}
+
+func testAccCheckRuleCount(server *cloudsqlfake.Server, expected int) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		if rules := server.Rules(); len(rules) != expected {
+			return fmt.Errorf("expected %d rules, got %d: %v", expected, len(rules), rules)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"strings"
+)
+
+// auditEvent describes a statement executed against the instance. Host is the
+// host the client connected from and Result is S for successful and U for
+// unsuccessful statements.
+type auditEvent struct {
+	User      string
+	Host      string
+	Db        string
+	Object    string
+	Operation string
+	Result    string
+}
+
+// ruleMatchesEvent reports whether the rule covers the event. Exclusion rules
+// (op_result E) match the events they exclude from the audit log.
+func ruleMatchesEvent(rule AuditRule, event auditEvent) bool {
+	return clientMatches(rule.Username, event.User, event.Host) &&
+		ruleFieldMatches(rule.Dbname, event.Db) &&
+		ruleFieldMatches(rule.Object, event.Object) &&
+		operationMatches(rule.Operation, event.Operation) &&
+		opResultMatches(rule.OpResult, event.Result)
+}
+
+// ruleFieldMatches matches a value against a comma separated list of
+// (possibly wildcard) audit rule entries.
+func ruleFieldMatches(pattern, value string) bool {
+	for _, entry := range splitRuleList(pattern) {
+		entry = unquoteIdentifier(entry)
+		if isWildcard(entry) || wildcardMatch(entry, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// operationMatches matches a statement against the comma separated rule
+// operations after expanding the operation classes, operations are case
+// insensitive.
+func operationMatches(pattern, operation string) bool {
+	operation = strings.ToLower(strings.TrimSpace(operation))
+
+	for _, entry := range expandOperations(pattern) {
+		if isWildcard(entry) || wildcardMatch(entry, operation) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func opResultMatches(opResult, result string) bool {
+	switch strings.ToUpper(opResult) {
+	case "B", "E":
+		return true
+	default:
+		return strings.EqualFold(opResult, result)
+	}
+}
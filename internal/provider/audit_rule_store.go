@@ -0,0 +1,132 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ErrAuditRuleNotFound is returned by the stores when a rule doesn't exist.
+var ErrAuditRuleNotFound = errors.New("audit rule not found")
+
+// ErrAuditRuleMetadataNotFound is returned by the metadata stores when a rule
+// has no metadata stored.
+var ErrAuditRuleMetadataNotFound = errors.New("audit rule metadata not found")
+
+// AuditRule is an audit rule as stored by the database engine.
+type AuditRule struct {
+	ID        int64
+	Username  string
+	Dbname    string
+	Object    string
+	Operation string
+	OpResult  string
+}
+
+// AuditRuleMetadata is the provider managed metadata attached to a rule.
+type AuditRuleMetadata struct {
+	RuleID      int64
+	Description sql.NullString
+	Owner       sql.NullString
+	Ticket      sql.NullString
+}
+
+// AuditRuleStore manages the audit rules of a single instance.
+type AuditRuleStore interface {
+	// List returns all the rules sorted by id.
+	List(ctx context.Context) ([]AuditRule, error)
+
+	// Get returns the rule with the given id.
+	Get(ctx context.Context, id int64) (AuditRule, error)
+
+	// Find returns the id of the rule matching all the fields of rule
+	// except for its id.
+	Find(ctx context.Context, rule AuditRule) (int64, error)
+
+	// Create adds a rule and returns its id.
+	Create(ctx context.Context, rule AuditRule) (int64, error)
+
+	// Update replaces the fields of the rule with the same id.
+	Update(ctx context.Context, rule AuditRule) error
+
+	// Delete removes the rule with the given id.
+	Delete(ctx context.Context, id int64) error
+
+	// Reload makes the audit plugin pick up the current rules.
+	Reload(ctx context.Context) error
+}
+
+// AuditRuleMetadataStore is implemented by the stores that can keep the rule
+// metadata next to the rules.
+type AuditRuleMetadataStore interface {
+	// MetadataAvailable reports whether metadata is stored next to the
+	// rules, when it isn't the metadata only lives in the terraform state.
+	MetadataAvailable(ctx context.Context) bool
+
+	// PrepareMetadata sets up the metadata storage if needed and reports
+	// whether the metadata can be stored, it is only called before writing
+	// metadata so that reads never change the instance.
+	PrepareMetadata(ctx context.Context) bool
+
+	// ListMetadata returns the metadata of all the rules.
+	ListMetadata(ctx context.Context) ([]AuditRuleMetadata, error)
+
+	// GetMetadata returns the metadata of a rule, ErrAuditRuleMetadataNotFound
+	// when none is stored for it.
+	GetMetadata(ctx context.Context, ruleID int64) (AuditRuleMetadata, error)
+
+	// SetMetadata creates or replaces the metadata of a rule.
+	SetMetadata(ctx context.Context, metadata AuditRuleMetadata) error
+
+	// DeleteMetadata removes the metadata of a rule.
+	DeleteMetadata(ctx context.Context, ruleID int64) error
+}
+
+// auditRuleStores maps the engines to the constructor of their audit rule
+// store, release must be called once the store is no longer needed.
+var auditRuleStores = map[string]func(ctx context.Context, conn *instanceConnection) (store AuditRuleStore, release func(), err error){
+	"mysql": newMySQLAuditRuleStore,
+}
+
+// auditRuleStore returns the audit rule store of the given instance for the
+// configured engine.
+func (c CloudSqlClientAndConfig) auditRuleStore(ctx context.Context, instance types.String) (AuditRuleStore, func(), error) {
+	newStore, ok := auditRuleStores[c.engine]
+	if !ok {
+		return nil, nil, fmt.Errorf("audit rules are not supported by the %q engine", c.engine)
+	}
+
+	conn, err := c.connection(ctx, instance)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newStore(ctx, conn)
+}
+
+// metadataStore returns the metadata store behind store if it can be used.
+func metadataStore(ctx context.Context, store AuditRuleStore) (AuditRuleMetadataStore, bool) {
+	metadata, ok := store.(AuditRuleMetadataStore)
+	if !ok || !metadata.MetadataAvailable(ctx) {
+		return nil, false
+	}
+
+	return metadata, true
+}
+
+// writableMetadataStore is metadataStore for writing metadata, the storage is
+// set up when it doesn't exist yet.
+func writableMetadataStore(ctx context.Context, store AuditRuleStore) (AuditRuleMetadataStore, bool) {
+	metadata, ok := store.(AuditRuleMetadataStore)
+	if !ok || !metadata.PrepareMetadata(ctx) {
+		return nil, false
+	}
+
+	return metadata, true
+}
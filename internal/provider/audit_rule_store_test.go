@@ -0,0 +1,374 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"terraform-provider-cloudsql-auditlog/internal/cloudsqlfake"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ AuditRuleStore         = &memoryAuditRuleStore{}
+	_ AuditRuleMetadataStore = &memoryAuditRuleStore{}
+)
+
+// memoryAuditRuleStore keeps the rules in memory, it is meant for the tests
+// that don't care about the database engine.
+type memoryAuditRuleStore struct {
+	mu       sync.Mutex
+	nextID   int64
+	rules    map[int64]AuditRule
+	metadata map[int64]AuditRuleMetadata
+
+	// metadataPrepared is set once metadata was written, like the table
+	// of the mysql store
+	metadataPrepared bool
+}
+
+func newMemoryAuditRuleStore() *memoryAuditRuleStore {
+	return &memoryAuditRuleStore{
+		nextID:   1,
+		rules:    make(map[int64]AuditRule),
+		metadata: make(map[int64]AuditRuleMetadata),
+	}
+}
+
+func (s *memoryAuditRuleStore) List(_ context.Context) ([]AuditRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules := make([]AuditRule, 0, len(s.rules))
+	for _, rule := range s.rules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	return rules, nil
+}
+
+func (s *memoryAuditRuleStore) Get(_ context.Context, id int64) (AuditRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, ok := s.rules[id]
+	if !ok {
+		return AuditRule{}, ErrAuditRuleNotFound
+	}
+
+	return rule, nil
+}
+
+func (s *memoryAuditRuleStore) Find(ctx context.Context, rule AuditRule) (int64, error) {
+	rules, _ := s.List(ctx)
+	for _, r := range rules {
+		rule.ID = r.ID
+		if r == rule {
+			return r.ID, nil
+		}
+	}
+
+	return 0, ErrAuditRuleNotFound
+}
+
+func (s *memoryAuditRuleStore) Create(_ context.Context, rule AuditRule) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule.ID = s.nextID
+	s.nextID++
+	s.rules[rule.ID] = rule
+
+	return rule.ID, nil
+}
+
+func (s *memoryAuditRuleStore) Update(_ context.Context, rule AuditRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.rules[rule.ID]; !ok {
+		return ErrAuditRuleNotFound
+	}
+	s.rules[rule.ID] = rule
+
+	return nil
+}
+
+func (s *memoryAuditRuleStore) Delete(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.rules[id]; !ok {
+		return ErrAuditRuleNotFound
+	}
+	delete(s.rules, id)
+
+	return nil
+}
+
+func (s *memoryAuditRuleStore) Reload(_ context.Context) error {
+	return nil
+}
+
+func (s *memoryAuditRuleStore) MetadataAvailable(_ context.Context) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.metadataPrepared
+}
+
+func (s *memoryAuditRuleStore) PrepareMetadata(_ context.Context) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.metadataPrepared = true
+
+	return true
+}
+
+func (s *memoryAuditRuleStore) ListMetadata(_ context.Context) ([]AuditRuleMetadata, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	metadata := make([]AuditRuleMetadata, 0, len(s.metadata))
+	for _, m := range s.metadata {
+		metadata = append(metadata, m)
+	}
+	sort.Slice(metadata, func(i, j int) bool { return metadata[i].RuleID < metadata[j].RuleID })
+
+	return metadata, nil
+}
+
+func (s *memoryAuditRuleStore) GetMetadata(_ context.Context, ruleID int64) (AuditRuleMetadata, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if m, ok := s.metadata[ruleID]; ok {
+		return m, nil
+	}
+
+	return AuditRuleMetadata{}, ErrAuditRuleMetadataNotFound
+}
+
+func (s *memoryAuditRuleStore) SetMetadata(_ context.Context, metadata AuditRuleMetadata) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.metadata[metadata.RuleID] = metadata
+
+	return nil
+}
+
+func (s *memoryAuditRuleStore) DeleteMetadata(_ context.Context, ruleID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.metadata, ruleID)
+
+	return nil
+}
+
+// testAuditRuleStore checks the behavior shared by all the store
+// implementations.
+func testAuditRuleStore(t *testing.T, store AuditRuleStore) {
+	ctx := context.Background()
+
+	rule := AuditRule{
+		Username:  "user@%",
+		Dbname:    "*",
+		Object:    "*",
+		Operation: "dml",
+		OpResult:  "B",
+	}
+
+	if _, err := store.Find(ctx, rule); !errors.Is(err, ErrAuditRuleNotFound) {
+		t.Fatalf("expected the rule not to be found, got %v", err)
+	}
+
+	missing := rule
+	missing.ID = 1000
+	if err := store.Update(ctx, missing); !errors.Is(err, ErrAuditRuleNotFound) {
+		t.Fatalf("expected updating a missing rule to fail with not found, got %v", err)
+	}
+	if err := store.Delete(ctx, missing.ID); !errors.Is(err, ErrAuditRuleNotFound) {
+		t.Fatalf("expected deleting a missing rule to fail with not found, got %v", err)
+	}
+
+	id, err := store.Create(ctx, rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule.ID = id
+
+	if found, err := store.Find(ctx, rule); err != nil || found != id {
+		t.Fatalf("expected to find rule %d, got %d: %v", id, found, err)
+	}
+
+	rule.Dbname = "app"
+	rule.OpResult = "S"
+	if err := store.Update(ctx, rule); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := store.Get(ctx, id); err != nil || got != rule {
+		t.Fatalf("expected %+v, got %+v: %v", rule, got, err)
+	}
+
+	if err := store.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	other := AuditRule{
+		Username:  "*",
+		Dbname:    "*",
+		Object:    "*",
+		Operation: "ddl",
+		OpResult:  "B",
+	}
+	other.ID, err = store.Create(ctx, other)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := store.List(ctx)
+	if err != nil || len(rules) != 2 || rules[0] != rule || rules[1] != other {
+		t.Fatalf("expected [%+v %+v] sorted by id, got %+v: %v", rule, other, rules, err)
+	}
+
+	for _, id := range []int64{other.ID, id} {
+		if err := store.Delete(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := store.Get(ctx, id); !errors.Is(err, ErrAuditRuleNotFound) {
+		t.Fatalf("expected the rule not to be found, got %v", err)
+	}
+}
+
+// testAuditRuleMetadataStore checks the behavior shared by all the metadata
+// store implementations.
+func testAuditRuleMetadataStore(t *testing.T, store AuditRuleStore) {
+	ctx := context.Background()
+
+	if _, ok := metadataStore(ctx, store); ok {
+		t.Fatal("expected reads not to create the metadata table")
+	}
+
+	metadata, ok := writableMetadataStore(ctx, store)
+	if !ok {
+		t.Fatal("expected metadata to be available")
+	}
+
+	if _, ok := metadataStore(ctx, store); !ok {
+		t.Fatal("expected the metadata table to be found once created")
+	}
+
+	if _, err := metadata.GetMetadata(ctx, 1); !errors.Is(err, ErrAuditRuleMetadataNotFound) {
+		t.Fatalf("expected no metadata, got %v", err)
+	}
+
+	want := AuditRuleMetadata{
+		RuleID:      1,
+		Description: sql.NullString{String: "description", Valid: true},
+	}
+	if err := metadata.SetMetadata(ctx, want); err != nil {
+		t.Fatal(err)
+	}
+
+	if m, err := metadata.GetMetadata(ctx, 1); err != nil || m != want {
+		t.Fatalf("expected %+v, got %+v: %v", want, m, err)
+	}
+
+	if all, err := metadata.ListMetadata(ctx); err != nil || len(all) != 1 || all[0] != want {
+		t.Fatalf("expected [%+v], got %+v: %v", want, all, err)
+	}
+
+	if err := metadata.DeleteMetadata(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if all, err := metadata.ListMetadata(ctx); err != nil || len(all) != 0 {
+		t.Fatalf("expected no metadata, got %+v: %v", all, err)
+	}
+}
+
+func newTestMySQLAuditRuleStore(t *testing.T) AuditRuleStore {
+	t.Helper()
+
+	conn := &instanceConnection{
+		db:       cloudsqlfake.New().DB(),
+		metadata: &auditRuleMetadataTable{},
+	}
+
+	store, release, err := newMySQLAuditRuleStore(context.Background(), conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(release)
+
+	return store
+}
+
+func TestMemoryAuditRuleStore(t *testing.T) {
+	testAuditRuleStore(t, newMemoryAuditRuleStore())
+	testAuditRuleMetadataStore(t, newMemoryAuditRuleStore())
+}
+
+func TestMySQLAuditRuleStore(t *testing.T) {
+	testAuditRuleStore(t, newTestMySQLAuditRuleStore(t))
+	testAuditRuleMetadataStore(t, newTestMySQLAuditRuleStore(t))
+}
+
+func TestMySQLAuditRuleStoreProcedureFailure(t *testing.T) {
+	store := newTestMySQLAuditRuleStore(t)
+
+	_, err := store.Create(context.Background(), AuditRule{
+		Username:  "user@%",
+		Dbname:    "*",
+		Object:    "*",
+		Operation: "dml",
+		OpResult:  "X",
+	})
+	if err == nil || errors.Is(err, ErrAuditRuleNotFound) || !strings.Contains(err.Error(), `Invalid op_result "X"`) {
+		t.Fatalf("expected the procedure error, got %v", err)
+	}
+}
+
+func TestAuditRuleMetadataTable(t *testing.T) {
+	ctx := context.Background()
+	server := cloudsqlfake.New()
+	server.DenyCreateTable = true
+	table := &auditRuleMetadataTable{}
+
+	if table.Available(ctx, server.DB(), true) {
+		t.Fatal("expected the metadata table to be unavailable without the create privilege")
+	}
+
+	// failures aren't remembered, the table can be created later
+	server.DenyCreateTable = false
+	if table.Available(ctx, server.DB(), false) {
+		t.Fatal("expected reads not to create the metadata table")
+	}
+	if !table.Available(ctx, server.DB(), true) || !server.MetadataTableExists() {
+		t.Fatal("expected the metadata table to be created")
+	}
+}
+
+func TestAuditRuleStoreEngine(t *testing.T) {
+	client := CloudSqlClientAndConfig{engine: "postgresql"}
+
+	if _, _, err := client.auditRuleStore(context.Background(), types.StringNull()); err == nil {
+		t.Fatal("expected engines without an audit rule store to fail")
+	}
+}
@@ -0,0 +1,422 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"terraform-provider-cloudsql-auditlog/db"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// defaultInstance is the name of the connection configured with the
+// top-level provider attributes.
+const defaultInstance = ""
+
+// connectionAttributes are the connection settings shared by the provider
+// configuration and each of its instances.
+type connectionAttributes struct {
+	Endpoint               types.String `tfsdk:"endpoint"`
+	Username               types.String `tfsdk:"username"`
+	Password               types.String `tfsdk:"password"`
+	PasswordCommand        types.List   `tfsdk:"password_command"`
+	PasswordCommandTimeout types.String `tfsdk:"password_command_timeout"`
+	PasswordFile           types.String `tfsdk:"password_file"`
+	Tls                    types.String `tfsdk:"tls"`
+	IamAuth                types.Bool   `tfsdk:"iam_auth"`
+}
+
+// isUnknown reports whether any of the attributes is only known during apply.
+func (a connectionAttributes) isUnknown() bool {
+	return a.Endpoint.IsUnknown() ||
+		a.Username.IsUnknown() ||
+		a.Password.IsUnknown() ||
+		a.PasswordCommand.IsUnknown() ||
+		a.PasswordCommandTimeout.IsUnknown() ||
+		a.PasswordFile.IsUnknown() ||
+		a.Tls.IsUnknown() ||
+		a.IamAuth.IsUnknown()
+}
+
+// connectionConfig holds everything needed to open a connection pool to an
+// instance.
+type connectionConfig struct {
+	engine                 string
+	endpoint               string
+	username               string
+	password               string
+	passwordCommand        []string
+	passwordCommandTimeout time.Duration
+	passwordFile           string
+	tls                    string
+
+	// iamAuth sends the password, an IAM access token, in cleartext which
+	// cloudsql requires for IAM database authentication
+	iamAuth bool
+
+	// database is the postgresql database to connect to, the pgaudit
+	// settings and grants are scoped to a single database
+	database string
+}
+
+// connectionConfigFromAttributes validates the connection attributes found
+// at base and converts them to a connectionConfig. The password file and
+// command are only run by resolvePassword.
+func connectionConfigFromAttributes(ctx context.Context, base path.Path, attrs connectionAttributes) (connectionConfig, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	cfg := connectionConfig{
+		endpoint:               attrs.Endpoint.ValueString(),
+		username:               attrs.Username.ValueString(),
+		password:               attrs.Password.ValueString(),
+		passwordCommandTimeout: defaultPasswordCommandTimeout,
+		passwordFile:           attrs.PasswordFile.ValueString(),
+		tls:                    "false",
+		iamAuth:                attrs.IamAuth.ValueBool(),
+	}
+
+	if !attrs.Tls.IsNull() {
+		cfg.tls = attrs.Tls.ValueString()
+	}
+
+	if cfg.endpoint == "" {
+		diags.AddAttributeError(
+			base.AtName("endpoint"),
+			"Missing mysql endpoint",
+			"Must set mysql endpoint",
+		)
+	}
+
+	if cfg.username == "" {
+		diags.AddAttributeError(
+			base.AtName("username"),
+			"Missing mysql username",
+			"Must set mysql username",
+		)
+	}
+
+	if cfg.iamAuth && cfg.tls == "false" {
+		diags.AddAttributeError(
+			base.AtName("iam_auth"),
+			"IAM authentication requires TLS",
+			"Must set tls when iam_auth is enabled, the token is sent as a cleartext password",
+		)
+	}
+
+	passwordSources := 0
+	for _, source := range []bool{attrs.Password.IsNull(), attrs.PasswordCommand.IsNull(), attrs.PasswordFile.IsNull()} {
+		if !source {
+			passwordSources++
+		}
+	}
+
+	if passwordSources > 1 {
+		diags.AddAttributeError(
+			base.AtName("password"),
+			"Conflicting password options",
+			"Only one of password, password_command and password_file can be set",
+		)
+	}
+
+	if !attrs.PasswordCommandTimeout.IsNull() {
+		timeout, err := time.ParseDuration(attrs.PasswordCommandTimeout.ValueString())
+		if err == nil && timeout <= 0 {
+			err = fmt.Errorf("timeout must be positive, got %s", timeout)
+		}
+
+		if err != nil {
+			diags.AddAttributeError(
+				base.AtName("password_command_timeout"),
+				"Invalid password command timeout",
+				err.Error(),
+			)
+		} else {
+			cfg.passwordCommandTimeout = timeout
+		}
+	}
+
+	if !attrs.PasswordCommand.IsNull() {
+		diags.Append(attrs.PasswordCommand.ElementsAs(ctx, &cfg.passwordCommand, false)...)
+	}
+
+	return cfg, diags
+}
+
+// resolvePassword reads the password from the password file or command, if
+// one is configured.
+func (c *connectionConfig) resolvePassword(ctx context.Context) error {
+	var err error
+
+	switch {
+	case c.passwordFile != "":
+		c.password, err = readPasswordFile(c.passwordFile)
+		if err != nil {
+			return fmt.Errorf("unable to read password file: %w", err)
+		}
+	case len(c.passwordCommand) > 0:
+		c.password, err = runPasswordCommand(ctx, c.passwordCommand, c.passwordCommandTimeout)
+		if err != nil {
+			return fmt.Errorf("unable to run password command: %w", err)
+		}
+	}
+
+	c.passwordFile = ""
+	c.passwordCommand = nil
+
+	return nil
+}
+
+// openConnection opens the connection pool to an instance, the tests replace
+// it to connect to the fake instances in internal/cloudsqlfake.
+var openConnection = openDatabase
+
+func openDatabase(cfg connectionConfig) (*sql.DB, error) {
+	if cfg.engine == "postgresql" {
+		return openPostgreSQL(cfg)
+	}
+
+	return openMySQL(cfg)
+}
+
+func mysqlConfig(cfg connectionConfig) *mysql.Config {
+	mysqlCfg := mysql.NewConfig()
+
+	mysqlCfg.User = cfg.username
+	mysqlCfg.Passwd = cfg.password
+	mysqlCfg.Net = "tcp"
+	mysqlCfg.Addr = cfg.endpoint
+	mysqlCfg.DBName = "mysql"
+	mysqlCfg.TLSConfig = cfg.tls
+	mysqlCfg.AllowCleartextPasswords = cfg.iamAuth
+
+	return mysqlCfg
+}
+
+func openMySQL(cfg connectionConfig) (*sql.DB, error) {
+	conn, err := mysql.NewConnector(mysqlConfig(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse connection options: %w", err)
+	}
+
+	return sql.OpenDB(conn), nil
+}
+
+// defaultPostgreSQLDatabase is the database used for the instance wide
+// queries, it exists on every cloudsql instance.
+const defaultPostgreSQLDatabase = "postgres"
+
+// postgreSQLSSLModes maps the tls values accepted for mysql to the libpq
+// sslmode, anything else is passed through.
+var postgreSQLSSLModes = map[string]string{
+	"false":       "disable",
+	"true":        "verify-full",
+	"skip-verify": "require",
+	"preferred":   "prefer",
+}
+
+func openPostgreSQL(cfg connectionConfig) (*sql.DB, error) {
+	database := cfg.database
+	if database == "" {
+		database = defaultPostgreSQLDatabase
+	}
+
+	sslMode, ok := postgreSQLSSLModes[cfg.tls]
+	if !ok {
+		sslMode = cfg.tls
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.username, cfg.password),
+		Host:     cfg.endpoint,
+		Path:     "/" + database,
+		RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+	}
+
+	conn, err := pq.NewConnector(dsn.String())
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse connection options: %w", err)
+	}
+
+	return sql.OpenDB(conn), nil
+}
+
+// instanceConnection is the connection pool to a single instance.
+type instanceConnection struct {
+	db       *sql.DB
+	metadata *auditRuleMetadataTable
+
+	// secrets are masked in the logs
+	secrets []string
+
+	// cfg is kept to open the pools to the other postgresql databases
+	cfg       connectionConfig
+	mu        sync.Mutex
+	databases map[string]*sql.DB
+}
+
+// database returns the connection pool to a database of a postgresql
+// instance, an empty name selects the default database.
+func (c *instanceConnection) database(name string) (*sql.DB, error) {
+	if name == "" || name == c.cfg.database || (c.cfg.database == "" && name == defaultPostgreSQLDatabase) {
+		return c.db, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if pool, ok := c.databases[name]; ok {
+		return pool, nil
+	}
+
+	cfg := c.cfg
+	cfg.database = name
+
+	pool, err := openConnection(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("database %q: %w", name, err)
+	}
+
+	if c.databases == nil {
+		c.databases = make(map[string]*sql.DB)
+	}
+	c.databases[name] = pool
+
+	return pool, nil
+}
+
+// tracedConn returns a single connection of the pool with every call traced
+// in the logs. release must be called once the connection is no longer needed
+// to return it to the pool.
+func (c *instanceConnection) tracedConn(ctx context.Context) (*tracingDBTX, func(), error) {
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	traced := &tracingDBTX{
+		conn:    conn,
+		secrets: c.secrets,
+	}
+
+	return traced, func() { conn.Close() }, nil
+}
+
+// queries returns the sqlc queries running on a single connection of the
+// pool, with every call traced in the logs. release must be called once the
+// queries are no longer needed to return the connection to the pool.
+func (c *instanceConnection) queries(ctx context.Context) (*db.Queries, func(), error) {
+	conn, release, err := c.tracedConn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return db.New(conn), release, nil
+}
+
+// instanceConnections opens the connection pools to the configured instances
+// the first time they are used and caches them for the provider lifetime.
+type instanceConnections struct {
+	mu      sync.Mutex
+	configs map[string]connectionConfig
+	open    map[string]*instanceConnection
+}
+
+func newInstanceConnections(configs map[string]connectionConfig) *instanceConnections {
+	return &instanceConnections{
+		configs: configs,
+		open:    make(map[string]*instanceConnection),
+	}
+}
+
+func (c *instanceConnections) get(ctx context.Context, instance string) (*instanceConnection, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if conn, ok := c.open[instance]; ok {
+		return conn, nil
+	}
+
+	cfg, ok := c.configs[instance]
+	if !ok && instance == defaultInstance {
+		return nil, fmt.Errorf("no default connection is configured, set instance to one of: %s", strings.Join(c.names(), ", "))
+	} else if !ok {
+		return nil, fmt.Errorf("instance %q is not configured", instance)
+	}
+
+	if err := cfg.resolvePassword(ctx); err != nil {
+		return nil, fmt.Errorf("instance %q: %w", instance, err)
+	}
+
+	pool, err := openConnection(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("instance %q: %w", instance, err)
+	}
+
+	conn := &instanceConnection{
+		db:       pool,
+		metadata: &auditRuleMetadataTable{},
+		cfg:      cfg,
+	}
+	if cfg.password != "" {
+		conn.secrets = append(conn.secrets, cfg.password)
+	}
+	c.open[instance] = conn
+
+	return conn, nil
+}
+
+// names returns the sorted names of the configured instances.
+func (c *instanceConnections) names() []string {
+	var names []string
+	for name := range c.configs {
+		if name != defaultInstance {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// deferDataSourceRead postpones reading a data source until the provider
+// configuration is known, data sources can't return unknown values so the
+// read fails when terraform doesn't support deferred actions.
+func deferDataSourceRead(req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if req.ClientCapabilities.DeferralAllowed {
+		resp.Deferred = &datasource.Deferred{
+			Reason: datasource.DeferredReasonProviderConfigUnknown,
+		}
+		return
+	}
+
+	resp.Diagnostics.AddError(
+		"Unknown provider configuration",
+		"The provider connection details are only known during apply, data sources can't be read until then.",
+	)
+}
+
+// cutLast slices s around the last instance of sep, like strings.Cut.
+func cutLast(s, sep string) (string, string, bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+
+	return s[:i], s[i+len(sep):], true
+}
@@ -0,0 +1,81 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func testConnectionAttributes() connectionAttributes {
+	return connectionAttributes{
+		Endpoint:               types.StringValue("127.0.0.1:3306"),
+		Username:               types.StringValue("auditor"),
+		Password:               types.StringValue("token"),
+		PasswordCommand:        types.ListNull(types.StringType),
+		PasswordCommandTimeout: types.StringNull(),
+		PasswordFile:           types.StringNull(),
+		Tls:                    types.StringNull(),
+		IamAuth:                types.BoolNull(),
+	}
+}
+
+func TestConnectionConfigIAMAuth(t *testing.T) {
+	attrs := testConnectionAttributes()
+	attrs.IamAuth = types.BoolValue(true)
+
+	_, diags := connectionConfigFromAttributes(context.Background(), path.Empty(), attrs)
+	if !diags.HasError() {
+		t.Fatal("expected iam_auth without tls to fail")
+	}
+
+	attrs.Tls = types.StringValue("skip-verify")
+	cfg, diags := connectionConfigFromAttributes(context.Background(), path.Empty(), attrs)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if !mysqlConfig(cfg).AllowCleartextPasswords {
+		t.Error("expected cleartext passwords to be allowed with iam_auth")
+	}
+
+	attrs.IamAuth = types.BoolNull()
+	cfg, _ = connectionConfigFromAttributes(context.Background(), path.Empty(), attrs)
+	if mysqlConfig(cfg).AllowCleartextPasswords {
+		t.Error("expected cleartext passwords to be refused without iam_auth")
+	}
+}
+
+func TestConnectionConfigPasswordCommandTimeout(t *testing.T) {
+	tests := []struct {
+		timeout string
+		want    time.Duration
+		valid   bool
+	}{
+		{"5s", 5 * time.Second, true},
+		{"1m30s", 90 * time.Second, true},
+		{"0s", defaultPasswordCommandTimeout, false},
+		{"-1s", defaultPasswordCommandTimeout, false},
+		{"soon", defaultPasswordCommandTimeout, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.timeout, func(t *testing.T) {
+			attrs := testConnectionAttributes()
+			attrs.PasswordCommandTimeout = types.StringValue(tt.timeout)
+
+			cfg, diags := connectionConfigFromAttributes(context.Background(), path.Empty(), attrs)
+			if diags.HasError() == tt.valid {
+				t.Fatalf("expected valid to be %t, got %v", tt.valid, diags)
+			}
+			if cfg.passwordCommandTimeout != tt.want {
+				t.Errorf("expected a timeout of %s, got %s", tt.want, cfg.passwordCommandTimeout)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &expandOperationsFunction{}
+
+func NewExpandOperationsFunction() function.Function {
+	return &expandOperationsFunction{}
+}
+
+type expandOperationsFunction struct{}
+
+func (f *expandOperationsFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "expand_operations"
+}
+
+func (f *expandOperationsFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Expand the operation classes of an audit rule operation",
+		Description: fmt.Sprintf("Returns the statements covered by a comma separated audit rule operation, "+
+			"expanding classes like dml, ddl, dcl, show and call (operation classes version %d).", operationClassesVersion),
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "operation",
+				Description: "Audit rule operation, e.g., \"ddl,dcl\".",
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.StringType,
+		},
+	}
+}
+
+func (f *expandOperationsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var operation string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &operation))
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, expandOperations(operation)))
+}
@@ -0,0 +1,41 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestExpandOperationsFunction(t *testing.T) {
+	tests := map[string]string{
+		"dml":            "insert,update,delete,replace,load",
+		"DQL, dcl":       "select,grant,revoke",
+		"select,dql":     "select",
+		"*":              "*",
+		"ddl,sel*":       "create,alter,drop,rename,truncate,sel*",
+		"connect,  show": "connect,show",
+		"":               "",
+	}
+
+	for operation, want := range tests {
+		t.Run(operation, func(t *testing.T) {
+			got, err := testRunFunction(t, NewExpandOperationsFunction(), types.ListUnknown(types.StringType), types.StringValue(operation))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var operations []string
+			for _, v := range got.(types.List).Elements() {
+				operations = append(operations, v.(types.String).ValueString())
+			}
+
+			if strings.Join(operations, ",") != want {
+				t.Errorf("expected %s, got %v", want, operations)
+			}
+		})
+	}
+}
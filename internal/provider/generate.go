@@ -0,0 +1,195 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const auditLogRuleResourceType = "cloudsql-auditlog_audit_log_rule"
+
+// GenerateOptions are the connection settings used by the generate command,
+// they mirror the provider configuration attributes.
+type GenerateOptions struct {
+	Engine                 string
+	Endpoint               string
+	Username               string
+	Password               string
+	PasswordCommand        []string
+	PasswordCommandTimeout time.Duration
+	PasswordFile           string
+	Tls                    string
+
+	// Instance is set as the instance attribute of the generated resources
+	// and prefixed to the import ids, for providers using instances.
+	Instance string
+}
+
+// Generate connects to an instance and writes a resource and an import block
+// for each of its audit rules to w.
+func Generate(ctx context.Context, opts GenerateOptions, w io.Writer) error {
+	attrs := connectionAttributes{
+		Endpoint:               optionalString(opts.Endpoint),
+		Username:               optionalString(opts.Username),
+		Password:               optionalString(opts.Password),
+		PasswordCommand:        types.ListNull(types.StringType),
+		PasswordCommandTimeout: types.StringNull(),
+		PasswordFile:           optionalString(opts.PasswordFile),
+		Tls:                    optionalString(opts.Tls),
+	}
+
+	if len(opts.PasswordCommand) > 0 {
+		var diags diag.Diagnostics
+		attrs.PasswordCommand, diags = types.ListValueFrom(ctx, types.StringType, opts.PasswordCommand)
+		if diags.HasError() {
+			return diagnosticsError(diags)
+		}
+	}
+
+	if opts.PasswordCommandTimeout != 0 {
+		attrs.PasswordCommandTimeout = types.StringValue(opts.PasswordCommandTimeout.String())
+	}
+
+	cfg, diags := connectionConfigFromAttributes(ctx, path.Empty(), attrs)
+	if diags.HasError() {
+		return diagnosticsError(diags)
+	}
+
+	client := CloudSqlClientAndConfig{
+		engine:      opts.Engine,
+		connections: newInstanceConnections(map[string]connectionConfig{defaultInstance: cfg}),
+	}
+
+	store, release, err := client.auditRuleStore(ctx, types.StringNull())
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	rules, err := store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to query audit rules: %w", err)
+	}
+
+	metadata := make(map[int64]AuditRuleMetadata)
+	if ms, ok := metadataStore(ctx, store); ok {
+		all, err := ms.ListMetadata(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to query audit rule metadata: %w", err)
+		}
+
+		for _, m := range all {
+			metadata[m.RuleID] = m
+		}
+	}
+
+	_, err = w.Write(generateConfig(rules, metadata, opts.Instance))
+	return err
+}
+
+// generateConfig returns the resource and import blocks for the rules. The
+// resource names only depend on the instance and the rule id so that running
+// the command again produces the same addresses.
+func generateConfig(rules []AuditRule, metadata map[int64]AuditRuleMetadata, instance string) []byte {
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+
+	for i, rule := range rules {
+		if i > 0 {
+			body.AppendNewline()
+		}
+
+		id := strconv.FormatInt(rule.ID, 10)
+		name := generatedResourceName(instance, rule.ID)
+
+		resource := body.AppendNewBlock("resource", []string{auditLogRuleResourceType, name}).Body()
+		if instance != "" {
+			resource.SetAttributeValue("instance", cty.StringVal(instance))
+			id = instance + "/" + id
+		}
+		resource.SetAttributeValue("username", cty.StringVal(rule.Username))
+		resource.SetAttributeValue("dbname", cty.StringVal(rule.Dbname))
+		resource.SetAttributeValue("object", cty.StringVal(rule.Object))
+		resource.SetAttributeValue("operation", cty.StringVal(rule.Operation))
+		resource.SetAttributeValue("op_result", cty.StringVal(rule.OpResult))
+
+		if m, ok := metadata[rule.ID]; ok {
+			setOptionalAttribute(resource, "description", m.Description)
+			setOptionalAttribute(resource, "owner", m.Owner)
+			setOptionalAttribute(resource, "ticket", m.Ticket)
+		}
+
+		body.AppendNewline()
+
+		imp := body.AppendNewBlock("import", nil).Body()
+		imp.SetAttributeTraversal("to", hcl.Traversal{
+			hcl.TraverseRoot{Name: auditLogRuleResourceType},
+			hcl.TraverseAttr{Name: name},
+		})
+		imp.SetAttributeValue("id", cty.StringVal(id))
+	}
+
+	return hclwrite.Format(f.Bytes())
+}
+
+// generatedResourceName returns the name of the resource generated for a
+// rule, e.g., rule_7 or rule_primary_7 when an instance is set.
+func generatedResourceName(instance string, id int64) string {
+	var name strings.Builder
+	name.WriteString("rule_")
+
+	if instance != "" {
+		for _, r := range instance {
+			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-' {
+				name.WriteRune(r)
+			} else {
+				name.WriteRune('_')
+			}
+		}
+		name.WriteString("_")
+	}
+
+	name.WriteString(strconv.FormatInt(id, 10))
+
+	return name.String()
+}
+
+// setOptionalAttribute sets the attribute only when the value is not null.
+func setOptionalAttribute(body *hclwrite.Body, name string, value sql.NullString) {
+	if value.Valid {
+		body.SetAttributeValue(name, cty.StringVal(value.String))
+	}
+}
+
+func optionalString(s string) types.String {
+	if s == "" {
+		return types.StringNull()
+	}
+
+	return types.StringValue(s)
+}
+
+// diagnosticsError converts the error diagnostics to a single error.
+func diagnosticsError(diags diag.Diagnostics) error {
+	var errs []error
+	for _, d := range diags.Errors() {
+		errs = append(errs, fmt.Errorf("%s: %s", d.Summary(), d.Detail()))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,143 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+
+	"terraform-provider-cloudsql-auditlog/internal/cloudsqlfake"
+)
+
+func TestGenerate(t *testing.T) {
+	server, _ := newTestInstance(t)
+	server.AddRule(cloudsqlfake.Rule{
+		Username:  "user@%",
+		Dbname:    "app",
+		Object:    "*",
+		Operation: "ddl,dcl",
+		OpResult:  "B",
+	})
+	server.AddRule(cloudsqlfake.Rule{
+		Username:  "*",
+		Dbname:    "*",
+		Object:    "*",
+		Operation: "*",
+		OpResult:  "E",
+	})
+
+	var out bytes.Buffer
+	err := Generate(context.Background(), GenerateOptions{
+		Engine:   "mysql",
+		Endpoint: "fake-TestGenerate",
+		Username: "root",
+	}, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `resource "cloudsql-auditlog_audit_log_rule" "rule_1" {
+  username  = "user@%"
+  dbname    = "app"
+  object    = "*"
+  operation = "ddl,dcl"
+  op_result = "B"
+}
+
+import {
+  to = cloudsql-auditlog_audit_log_rule.rule_1
+  id = "1"
+}
+
+resource "cloudsql-auditlog_audit_log_rule" "rule_2" {
+  username  = "*"
+  dbname    = "*"
+  object    = "*"
+  operation = "*"
+  op_result = "E"
+}
+
+import {
+  to = cloudsql-auditlog_audit_log_rule.rule_2
+  id = "2"
+}
+`
+	if out.String() != want {
+		t.Fatalf("unexpected configuration:\n%s", out.String())
+	}
+}
+
+func TestGenerateConfigInstance(t *testing.T) {
+	out := generateConfig([]AuditRule{{
+		ID:        7,
+		Username:  "`app.user`@10.%",
+		Dbname:    "*",
+		Object:    "*",
+		Operation: "*",
+		OpResult:  "S",
+	}}, nil, "eu-west.primary")
+
+	want := `resource "cloudsql-auditlog_audit_log_rule" "rule_eu-west_primary_7" {
+  instance  = "eu-west.primary"
+  username  = "` + "`app.user`" + `@10.%"
+  dbname    = "*"
+  object    = "*"
+  operation = "*"
+  op_result = "S"
+}
+
+import {
+  to = cloudsql-auditlog_audit_log_rule.rule_eu-west_primary_7
+  id = "eu-west.primary/7"
+}
+`
+	if string(out) != want {
+		t.Fatalf("unexpected configuration:\n%s", out)
+	}
+}
+
+func TestGenerateConfigMetadata(t *testing.T) {
+	out := generateConfig([]AuditRule{{
+		ID:        3,
+		Username:  "app@%",
+		Dbname:    "app",
+		Object:    "*",
+		Operation: "ddl",
+		OpResult:  "B",
+	}}, map[int64]AuditRuleMetadata{
+		3: {
+			RuleID:      3,
+			Description: sql.NullString{String: "schema changes", Valid: true},
+			Ticket:      sql.NullString{String: "SEC-42", Valid: true},
+		},
+	}, "")
+
+	want := `resource "cloudsql-auditlog_audit_log_rule" "rule_3" {
+  username    = "app@%"
+  dbname      = "app"
+  object      = "*"
+  operation   = "ddl"
+  op_result   = "B"
+  description = "schema changes"
+  ticket      = "SEC-42"
+}
+
+import {
+  to = cloudsql-auditlog_audit_log_rule.rule_3
+  id = "3"
+}
+`
+	if string(out) != want {
+		t.Fatalf("unexpected configuration:\n%s", out)
+	}
+}
+
+func TestGenerateMissingEndpoint(t *testing.T) {
+	err := Generate(context.Background(), GenerateOptions{Engine: "mysql", Username: "root"}, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected a missing endpoint to fail")
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/oauth2/google"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ ephemeral.EphemeralResource = &iamTokenEphemeralResource{}
+
+// sqlserviceLoginScope is the scope required to log into cloudsql with IAM
+// database authentication.
+const sqlserviceLoginScope = "https://www.googleapis.com/auth/sqlservice.login"
+
+func NewIAMTokenEphemeralResource() ephemeral.EphemeralResource {
+	return &iamTokenEphemeralResource{}
+}
+
+// iamTokenEphemeralResource mints an OAuth2 access token from the
+// Application Default Credentials that can be used as the provider password
+// with cloudsql IAM database authentication, without it ever being stored.
+type iamTokenEphemeralResource struct{}
+
+type iamTokenEphemeralResourceModel struct {
+	Scopes    []types.String `tfsdk:"scopes"`
+	TokenURL  types.String   `tfsdk:"token_url"`
+	Token     types.String   `tfsdk:"token"`
+	ExpiresAt types.String   `tfsdk:"expires_at"`
+}
+
+func (r *iamTokenEphemeralResource) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_iam_token"
+}
+
+func (r *iamTokenEphemeralResource) Schema(_ context.Context, _ ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"scopes": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			// overrides the token endpoint used with user credentials,
+			// mostly useful to point the resource to a fake in tests
+			"token_url": schema.StringAttribute{
+				Optional: true,
+			},
+			"token": schema.StringAttribute{
+				Computed:  true,
+				Sensitive: true,
+			},
+			"expires_at": schema.StringAttribute{
+				Computed: true,
+			},
+		},
+	}
+}
+
+func (r *iamTokenEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data iamTokenEphemeralResourceModel
+	diags := req.Config.Get(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	scopes := []string{sqlserviceLoginScope}
+	if len(data.Scopes) > 0 {
+		scopes = scopes[:0]
+		for _, scope := range data.Scopes {
+			scopes = append(scopes, scope.ValueString())
+		}
+	}
+
+	creds, err := google.FindDefaultCredentialsWithParams(ctx, google.CredentialsParams{
+		Scopes:   scopes,
+		TokenURL: data.TokenURL.ValueString(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to find default credentials",
+			err.Error(),
+		)
+		return
+	}
+
+	token, err := creds.TokenSource.Token()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to retrieve access token",
+			err.Error(),
+		)
+		return
+	}
+
+	data.Token = types.StringValue(token.AccessToken)
+	data.ExpiresAt = types.StringNull()
+	if !token.Expiry.IsZero() {
+		data.ExpiresAt = types.StringValue(token.Expiry.UTC().Format(time.RFC3339))
+	}
+
+	diags = resp.Result.Set(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+}
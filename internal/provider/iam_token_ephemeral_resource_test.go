@@ -0,0 +1,133 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+// testTokenServer sets up Application Default Credentials for a user whose
+// tokens are minted by the returned fake token endpoint.
+func testTokenServer(t *testing.T) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Form.Get("refresh_token") != "refresh" {
+			http.Error(w, "invalid refresh token", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token": "iam-token", "token_type": "Bearer", "expires_in": 3600}`)
+	}))
+	t.Cleanup(server.Close)
+
+	credentials := filepath.Join(t.TempDir(), "credentials.json")
+	err := os.WriteFile(credentials, []byte(`{
+  "type": "authorized_user",
+  "client_id": "client",
+  "client_secret": "secret",
+  "refresh_token": "refresh"
+}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", credentials)
+
+	return server.URL
+}
+
+func TestIAMTokenEphemeralResourceOpen(t *testing.T) {
+	ctx := context.Background()
+	tokenURL := testTokenServer(t)
+
+	r := NewIAMTokenEphemeralResource()
+
+	var schemaResp ephemeral.SchemaResponse
+	r.Schema(ctx, ephemeral.SchemaRequest{}, &schemaResp)
+	schemaType := schemaResp.Schema.Type().TerraformType(ctx)
+
+	req := ephemeral.OpenRequest{
+		Config: tfsdk.Config{
+			Schema: schemaResp.Schema,
+			Raw: tftypes.NewValue(schemaType, map[string]tftypes.Value{
+				"scopes":     tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, nil),
+				"token_url":  tftypes.NewValue(tftypes.String, tokenURL),
+				"token":      tftypes.NewValue(tftypes.String, nil),
+				"expires_at": tftypes.NewValue(tftypes.String, nil),
+			}),
+		},
+	}
+	resp := &ephemeral.OpenResponse{
+		Result: tfsdk.EphemeralResultData{Schema: schemaResp.Schema},
+	}
+	r.Open(ctx, req, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+
+	var result iamTokenEphemeralResourceModel
+	if diags := resp.Result.Get(ctx, &result); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if result.Token.ValueString() != "iam-token" {
+		t.Errorf("expected the minted token, got %q", result.Token.ValueString())
+	}
+	if result.ExpiresAt.IsNull() {
+		t.Error("expected the token expiry to be set")
+	}
+}
+
+func TestAccIAMTokenEphemeralResource(t *testing.T) {
+	server, providerConfig := newTestInstance(t)
+	tokenURL := testTokenServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + fmt.Sprintf(`
+ephemeral "cloudsql-auditlog_iam_token" "login" {
+  token_url = %[1]q
+}
+
+provider "cloudsql-auditlog" {
+  alias    = "iam"
+  engine   = "mysql"
+  endpoint = "fake-%[2]s"
+  username = "terraform"
+  password = ephemeral.cloudsql-auditlog_iam_token.login.token
+  tls      = "skip-verify"
+  iam_auth = true
+}
+
+resource "cloudsql-auditlog_audit_log_rule" "test" {
+  provider  = cloudsql-auditlog.iam
+  username  = "*"
+  dbname    = "*"
+  object    = "*"
+  operation = "ddl"
+  op_result = "B"
+}
+`, tokenURL, t.Name()),
+				Check: testAccCheckRuleCount(server, 1),
+			},
+		},
+	})
+}
@@ -0,0 +1,258 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+const getAllMySQLUsers = "SELECT User, Host FROM mysql.user ORDER BY User, Host"
+
+// mysqlAccount is a row of the mysql.user table.
+type mysqlAccount struct {
+	User string
+	Host string
+}
+
+func readMySQLAccounts(ctx context.Context, conn *sql.DB) ([]mysqlAccount, error) {
+	rows, err := conn.QueryContext(ctx, getAllMySQLUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []mysqlAccount
+	for rows.Next() {
+		var a mysqlAccount
+		if err := rows.Scan(&a.User, &a.Host); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, a)
+	}
+
+	return accounts, rows.Err()
+}
+
+// splitRuleList splits a comma separated audit rule field into its entries,
+// commas inside backtick quoted identifiers are not treated as separators.
+func splitRuleList(s string) []string {
+	var entries []string
+	var current strings.Builder
+	quoted := false
+
+	for _, c := range s {
+		switch {
+		case c == '`':
+			quoted = !quoted
+			current.WriteRune(c)
+		case c == ',' && !quoted:
+			entries = append(entries, strings.TrimSpace(current.String()))
+			current.Reset()
+		default:
+			current.WriteRune(c)
+		}
+	}
+
+	return append(entries, strings.TrimSpace(current.String()))
+}
+
+// splitRuleAccount splits a rule username in the user@host format into its
+// unquoted user and host parts. A missing host is the same as any host.
+func splitRuleAccount(account string) (string, string) {
+	quoted := false
+	at := -1
+
+	for i, c := range account {
+		switch {
+		case c == '`':
+			quoted = !quoted
+		case c == '@' && !quoted:
+			at = i
+		}
+	}
+
+	if at < 0 {
+		return unquoteIdentifier(account), "*"
+	}
+
+	return unquoteIdentifier(account[:at]), unquoteIdentifier(account[at+1:])
+}
+
+// unquoteIdentifier removes backtick, single or double quotes around s.
+func unquoteIdentifier(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 {
+		return s
+	}
+
+	switch q := s[0]; q {
+	case '`', '\'', '"':
+		if s[len(s)-1] == q {
+			inner := s[1 : len(s)-1]
+			return strings.ReplaceAll(inner, string([]byte{q, q}), string(q))
+		}
+	}
+
+	return s
+}
+
+// isWildcard reports whether an audit rule entry matches everything, only *
+// is a wildcard in the audit rules.
+func isWildcard(s string) bool {
+	return s == "*" || s == ""
+}
+
+// hasWildcard reports whether an audit rule entry contains a wildcard.
+func hasWildcard(s string) bool {
+	return strings.Contains(s, "*")
+}
+
+// wildcardMatch matches s against an audit rule pattern where * matches any
+// (possibly empty) sequence of characters, everything else including the %
+// of mysql.user host patterns is matched literally. The comparison is case
+// sensitive like the utf8mb4_bin collation of audit_log_rules.
+func wildcardMatch(pattern, s string) bool {
+	return patternMatch([]rune(pattern), []rune(s), "*", "")
+}
+
+// clientHostMatch matches the host a client connected from against the host
+// of an audit rule account, which is a mysql.user host pattern where % and _
+// are the LIKE wildcards, as well as the * of the audit rules. Host names are
+// case insensitive.
+func clientHostMatch(pattern, host string) bool {
+	return patternMatch([]rune(strings.ToLower(pattern)), []rune(strings.ToLower(host)), "*%", "_")
+}
+
+// patternMatch matches s against the pattern where the runes in many match
+// any (possibly empty) sequence and the runes in one any single rune.
+func patternMatch(pr, sr []rune, many, one string) bool {
+	p, i := 0, 0
+	starP, starI := -1, 0
+
+	for i < len(sr) {
+		switch {
+		case p < len(pr) && strings.ContainsRune(many, pr[p]):
+			starP, starI = p, i
+			p++
+		case p < len(pr) && (pr[p] == sr[i] || strings.ContainsRune(one, pr[p])):
+			p++
+			i++
+		case starP >= 0:
+			starI++
+			p, i = starP+1, starI
+		default:
+			return false
+		}
+	}
+
+	for p < len(pr) && strings.ContainsRune(many, pr[p]) {
+		p++
+	}
+
+	return p == len(pr)
+}
+
+// accountMatches reports whether the rule username pattern (in the user@host
+// format, possibly a comma separated list) covers the given account. The host
+// is matched against the host pattern of the account, so a rule for app@%
+// only covers the app@% account and not app@10.%, which app@* or app covers.
+func accountMatches(pattern string, account mysqlAccount) bool {
+	for _, entry := range splitRuleList(pattern) {
+		if isWildcard(entry) {
+			return true
+		}
+
+		user, host := splitRuleAccount(entry)
+		if !isWildcard(user) && !wildcardMatch(user, account.User) {
+			continue
+		}
+
+		if isWildcard(host) || wildcardMatch(host, account.Host) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// clientMatches reports whether the rule username pattern covers a client
+// connected as user from host, e.g., app@% covers app connecting from
+// 10.0.0.5 while accountMatches only covers the app@% account.
+func clientMatches(pattern, user, host string) bool {
+	for _, entry := range splitRuleList(pattern) {
+		if isWildcard(entry) {
+			return true
+		}
+
+		ruleUser, ruleHost := splitRuleAccount(entry)
+		if !isWildcard(ruleUser) && !wildcardMatch(ruleUser, user) {
+			continue
+		}
+
+		if isWildcard(ruleHost) || clientHostMatch(ruleHost, host) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchingAccounts returns the accounts covered by the rule username pattern.
+func matchingAccounts(pattern string, accounts []mysqlAccount) []mysqlAccount {
+	var matches []mysqlAccount
+	for _, a := range accounts {
+		if accountMatches(pattern, a) {
+			matches = append(matches, a)
+		}
+	}
+
+	return matches
+}
+
+// formatRuleAccount builds the user@host username stored by cloudsql in
+// audit_log_rules. Users are quoted with backticks unless they only contain
+// characters that never need quoting or are the * wildcard, hosts keep their
+// % patterns and an empty host means any host like a missing host does in
+// splitRuleAccount.
+func formatRuleAccount(user, host string) (string, error) {
+	if user == "" {
+		return "", errors.New("user must not be empty")
+	}
+
+	if host == "" {
+		host = "*"
+	}
+
+	if user != "*" && !isPlainIdentifier(user, "_$") {
+		user = quoteIdentifier(user)
+	}
+
+	if !isPlainIdentifier(host, "_$.%*:-") {
+		host = quoteIdentifier(host)
+	}
+
+	return user + "@" + host, nil
+}
+
+// isPlainIdentifier reports whether s only consists of ASCII letters, digits
+// and the extra allowed characters.
+func isPlainIdentifier(s, extra string) bool {
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune(extra, c):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func quoteIdentifier(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
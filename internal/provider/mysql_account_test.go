@@ -0,0 +1,92 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+)
+
+func TestSplitRuleAccount(t *testing.T) {
+	tests := []struct {
+		account string
+		user    string
+		host    string
+	}{
+		{"app@%", "app", "%"},
+		{"app@10.%", "app", "10.%"},
+		{"app", "app", "*"},
+		{"*", "*", "*"},
+		{"`app@ops`@localhost", "app@ops", "localhost"},
+		{"'app'@'%'", "app", "%"},
+		{"app@", "app", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.account, func(t *testing.T) {
+			user, host := splitRuleAccount(tt.account)
+			if user != tt.user || host != tt.host {
+				t.Errorf("expected %q and %q, got %q and %q", tt.user, tt.host, user, host)
+			}
+		})
+	}
+}
+
+func TestAccountMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		account mysqlAccount
+		want    bool
+	}{
+		{"*", mysqlAccount{User: "app", Host: "10.%"}, true},
+		{"app", mysqlAccount{User: "app", Host: "10.%"}, true},
+		{"app@*", mysqlAccount{User: "app", Host: "10.%"}, true},
+		{"app@%", mysqlAccount{User: "app", Host: "%"}, true},
+		{"app@%", mysqlAccount{User: "app", Host: "10.%"}, false},
+		{"app@10.*", mysqlAccount{User: "app", Host: "10.%"}, true},
+		{"app@10.0.0.1", mysqlAccount{User: "app", Host: "10.%"}, false},
+		{"*@%", mysqlAccount{User: "report", Host: "%"}, true},
+		{"app*@%", mysqlAccount{User: "appreport", Host: "%"}, true},
+		{"app@%", mysqlAccount{User: "App", Host: "%"}, false},
+		{"report@%,app@localhost", mysqlAccount{User: "app", Host: "localhost"}, true},
+		{"`app`@`%`", mysqlAccount{User: "app", Host: "%"}, true},
+		{"%", mysqlAccount{User: "app", Host: "%"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.account.User+"@"+tt.account.Host, func(t *testing.T) {
+			if got := accountMatches(tt.pattern, tt.account); got != tt.want {
+				t.Errorf("expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClientMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		user    string
+		host    string
+		want    bool
+	}{
+		{"app@%", "app", "10.0.0.5", true},
+		{"app", "app", "10.0.0.5", true},
+		{"app@10.%", "app", "10.0.0.5", true},
+		{"app@10.%", "app", "192.168.0.5", false},
+		{"app@10.0.0._", "app", "10.0.0.5", true},
+		{"app@10.0.0._", "app", "10.0.0.15", false},
+		{"app@10.*", "app", "10.0.0.5", true},
+		{"app@Localhost", "app", "localhost", true},
+		{"app@%", "report", "10.0.0.5", false},
+		{"app*@%", "appreport", "10.0.0.5", true},
+		{"report@%,app@localhost", "app", "localhost", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.user+"@"+tt.host, func(t *testing.T) {
+			if got := clientMatches(tt.pattern, tt.user, tt.host); got != tt.want {
+				t.Errorf("expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,626 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &auditLogBaselineResource{}
+	_ resource.ResourceWithConfigure      = &auditLogBaselineResource{}
+	_ resource.ResourceWithModifyPlan     = &auditLogBaselineResource{}
+	_ resource.ResourceWithValidateConfig = &auditLogBaselineResource{}
+)
+
+func NewAuditLogBaselineResource() resource.Resource {
+	return &auditLogBaselineResource{}
+}
+
+// auditLogBaselineResource manages the audit rules of a compliance baseline
+// preset. The resource owns its rules, rules changed or deleted outside of
+// terraform are restored by the next apply.
+type auditLogBaselineResource struct {
+	client CloudSqlClientAndConfig
+}
+
+type auditLogBaselineResourceModel struct {
+	ID                 types.String `tfsdk:"id"`
+	Instance           types.String `tfsdk:"instance"`
+	Preset             types.String `tfsdk:"preset"`
+	Version            types.String `tfsdk:"version"`
+	Exclusions         types.Set    `tfsdk:"exclusions"`
+	PrivilegedAccounts types.List   `tfsdk:"privileged_accounts"`
+	Rules              types.List   `tfsdk:"rules"`
+}
+
+type auditLogBaselineRuleModel struct {
+	Name      types.String `tfsdk:"name"`
+	ID        types.String `tfsdk:"id"`
+	Username  types.String `tfsdk:"username"`
+	DbName    types.String `tfsdk:"dbname"`
+	Object    types.String `tfsdk:"object"`
+	Operation types.String `tfsdk:"operation"`
+	OpResult  types.String `tfsdk:"op_result"`
+}
+
+var auditLogBaselineRuleType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"name":      types.StringType,
+	"id":        types.StringType,
+	"username":  types.StringType,
+	"dbname":    types.StringType,
+	"object":    types.StringType,
+	"operation": types.StringType,
+	"op_result": types.StringType,
+}}
+
+// baselineEntry is a named rule of the baseline, the rule ids are kept next
+// to the entries so that the entries can be compared.
+type baselineEntry struct {
+	name string
+	rule AuditRule
+}
+
+func (r *auditLogBaselineResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_audit_log_baseline"
+}
+
+func (r *auditLogBaselineResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"instance": schema.StringAttribute{
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"preset": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"version": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+			},
+			"exclusions": schema.SetAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"privileged_accounts": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"rules": schema.ListAttribute{
+				Computed:    true,
+				ElementType: auditLogBaselineRuleType,
+			},
+		},
+	}
+}
+
+func (r *auditLogBaselineResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config auditLogBaselineResourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.Preset.IsUnknown() || config.Version.IsUnknown() || config.Exclusions.IsUnknown() {
+		return
+	}
+
+	if _, ok := baselinePresets[config.Preset.ValueString()]; !ok {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("preset"),
+			"Invalid preset",
+			fmt.Sprintf("Invalid preset %q, allowed values: %s.", config.Preset.ValueString(), strings.Join(baselinePresetNames(), ", ")),
+		)
+		return
+	}
+
+	preset, ok := baselinePreset(config.Preset.ValueString(), config.Version.ValueString())
+	if !ok {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("version"),
+			"Invalid preset version",
+			fmt.Sprintf("Preset %q has no version %q.", config.Preset.ValueString(), config.Version.ValueString()),
+		)
+		return
+	}
+
+	var exclusions []string
+	resp.Diagnostics.Append(config.Exclusions.ElementsAs(ctx, &exclusions, false)...)
+	for _, exclusion := range exclusions {
+		if !slices.Contains(preset.ruleNames(), exclusion) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("exclusions"),
+				"Invalid exclusion",
+				fmt.Sprintf("Preset %q version %s has no rule %q, rules: %s.",
+					config.Preset.ValueString(), preset.version, exclusion, strings.Join(preset.ruleNames(), ", ")),
+			)
+		}
+	}
+}
+
+// ModifyPlan expands the preset so that the plan shows the rules, the ids
+// of the rules that are kept come from the state.
+func (r *auditLogBaselineResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to expand when the resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan auditLogBaselineResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// without a pinned version the baseline follows the latest version
+	var config types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("version"), &config)...)
+	if config.IsNull() && !plan.Preset.IsUnknown() {
+		if preset, ok := baselinePreset(plan.Preset.ValueString(), ""); ok {
+			plan.Version = types.StringValue(preset.version)
+		}
+	}
+
+	entries, known, diags := plan.baselineRules(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || !known {
+		return
+	}
+
+	// the expanded rules are held to the same guardrails as the
+	// audit_log_rule resources
+	for _, entry := range entries {
+		for _, violation := range r.client.guardrails.check(entry.rule) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("preset"),
+				"Audit log baseline violates the provider guardrails",
+				fmt.Sprintf("Rule %s: %s", entry.name, violation.message),
+			)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var current []baselineEntry
+	var ids []int64
+	if !req.State.Raw.IsNull() {
+		var state auditLogBaselineResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		current, ids, diags = state.stateRules(ctx)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// changed rules are updated in place and keep their id
+	var models []auditLogBaselineRuleModel
+	for _, entry := range entries {
+		id := types.StringUnknown()
+		if i := slices.IndexFunc(current, func(e baselineEntry) bool { return e.name == entry.name }); i >= 0 {
+			id = types.StringValue(strconv.FormatInt(ids[i], 10))
+		}
+		models = append(models, baselineRuleModel(entry, id))
+	}
+
+	plan.Rules, diags = types.ListValueFrom(ctx, auditLogBaselineRuleType, models)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.Plan.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *auditLogBaselineResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan auditLogBaselineResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = plan.Preset
+	if !plan.Instance.IsNull() {
+		plan.ID = types.StringValue(plan.Instance.ValueString() + "/" + plan.Preset.ValueString())
+	}
+
+	resp.Diagnostics.Append(r.apply(ctx, &plan, nil, nil)...)
+
+	// the rules created before an error are kept in the state
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *auditLogBaselineResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state auditLogBaselineResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the rules can't be refreshed until the provider configuration is
+	// known, keep the current state until then
+	if r.client.unknown {
+		return
+	}
+
+	current, ids, diags := state.stateRules(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	store, release, err := r.client.auditRuleStore(ctx, state.Instance)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+	defer release()
+
+	var refreshed []baselineEntry
+	var refreshedIDs []int64
+	for i, entry := range current {
+		rule, err := store.Get(ctx, ids[i])
+		if errors.Is(err, ErrAuditRuleNotFound) {
+			// deleted outside of terraform, created again by the next apply
+			continue
+		} else if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading audit log rule",
+				fmt.Sprintf("Could not read rule with id %d: %s", ids[i], err.Error()),
+			)
+			return
+		}
+
+		rule.ID = 0
+		refreshed = append(refreshed, baselineEntry{name: entry.name, rule: rule})
+		refreshedIDs = append(refreshedIDs, ids[i])
+	}
+
+	state.Rules, diags = baselineRulesValue(ctx, refreshed, refreshedIDs)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *auditLogBaselineResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state auditLogBaselineResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	current, ids, diags := state.stateRules(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.apply(ctx, &plan, current, ids)...)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *auditLogBaselineResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state auditLogBaselineResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, ids, diags := state.stateRules(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	store, release, err := r.client.auditRuleStore(ctx, state.Instance)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+	defer release()
+
+	for _, id := range ids {
+		if err := store.Delete(ctx, id); err != nil && !errors.Is(err, ErrAuditRuleNotFound) {
+			resp.Diagnostics.AddError(
+				"Unable to call audit rule delete",
+				err.Error(),
+			)
+			return
+		}
+	}
+}
+
+// apply makes the rules of the baseline match its preset: rules that
+// changed are updated in place, excluded ones deleted and missing ones
+// created. m.Rules is set to the rules that exist afterwards.
+func (r *auditLogBaselineResource) apply(ctx context.Context, m *auditLogBaselineResourceModel, current []baselineEntry, ids []int64) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	// the version is only left unknown by the plan when the preset was
+	// changed, use its latest version
+	if m.Version.IsUnknown() || m.Version.IsNull() {
+		if preset, ok := baselinePreset(m.Preset.ValueString(), ""); ok {
+			m.Version = types.StringValue(preset.version)
+		}
+	}
+
+	planned, _, d := m.baselineRules(ctx)
+	diags.Append(d...)
+	if diags.HasError() {
+		m.Rules, d = baselineRulesValue(ctx, current, ids)
+		diags.Append(d...)
+		return diags
+	}
+
+	store, release, err := r.client.auditRuleStore(ctx, m.Instance)
+	if err != nil {
+		diags.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		m.Rules, d = baselineRulesValue(ctx, current, ids)
+		diags.Append(d...)
+		return diags
+	}
+	defer release()
+
+	existing := make(map[string]int64)
+	for i, entry := range current {
+		existing[entry.name] = ids[i]
+	}
+
+	// the rules of the baseline as they are after each step, in case one
+	// of them fails
+	rules := make(map[string]baselineEntry)
+	for _, entry := range current {
+		rules[entry.name] = entry
+	}
+
+	for _, entry := range current {
+		if slices.ContainsFunc(planned, func(p baselineEntry) bool { return p.name == entry.name }) {
+			continue
+		}
+
+		if err := store.Delete(ctx, existing[entry.name]); err != nil && !errors.Is(err, ErrAuditRuleNotFound) {
+			diags.AddError(
+				"Unable to call audit rule delete",
+				err.Error(),
+			)
+			break
+		}
+		delete(rules, entry.name)
+		delete(existing, entry.name)
+	}
+
+	for _, entry := range planned {
+		if diags.HasError() {
+			break
+		}
+
+		if current, ok := rules[entry.name]; ok && current == entry {
+			continue
+		} else if ok {
+			rule := entry.rule
+			rule.ID = existing[entry.name]
+			if err := store.Update(ctx, rule); err != nil {
+				diags.AddError(
+					"Unable to call audit rule update",
+					err.Error(),
+				)
+				break
+			}
+			rules[entry.name] = entry
+			continue
+		}
+
+		if id, err := store.Find(ctx, entry.rule); err == nil {
+			diags.AddError(
+				"Rule already exists",
+				fmt.Sprintf("The %s rule of the baseline already exists with ID %d.", entry.name, id),
+			)
+			break
+		} else if !errors.Is(err, ErrAuditRuleNotFound) {
+			diags.AddError(
+				"Unable to check rule existence",
+				err.Error(),
+			)
+			break
+		}
+
+		id, err := store.Create(ctx, entry.rule)
+		if err != nil {
+			diags.AddError(
+				"Unable to call audit rule create",
+				err.Error(),
+			)
+			break
+		}
+		rules[entry.name] = entry
+		existing[entry.name] = id
+	}
+
+	// planned order first, then the rules that couldn't be deleted
+	var entries []baselineEntry
+	var entryIDs []int64
+	for _, entry := range slices.Concat(planned, current) {
+		if stored, ok := rules[entry.name]; ok && !slices.ContainsFunc(entries, func(e baselineEntry) bool { return e.name == entry.name }) {
+			entries = append(entries, stored)
+			entryIDs = append(entryIDs, existing[entry.name])
+		}
+	}
+
+	m.Rules, d = baselineRulesValue(ctx, entries, entryIDs)
+	diags.Append(d...)
+
+	return diags
+}
+
+// baselineRules expands the preset of the model, known is false when the
+// configuration is only known during apply.
+func (m auditLogBaselineResourceModel) baselineRules(ctx context.Context) ([]baselineEntry, bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if m.Preset.IsUnknown() || m.Version.IsUnknown() || m.Exclusions.IsUnknown() || m.PrivilegedAccounts.IsUnknown() {
+		return nil, false, diags
+	}
+
+	preset, ok := baselinePreset(m.Preset.ValueString(), m.Version.ValueString())
+	if !ok {
+		diags.AddAttributeError(
+			path.Root("version"),
+			"Invalid preset version",
+			fmt.Sprintf("Preset %q has no version %q.", m.Preset.ValueString(), m.Version.ValueString()),
+		)
+		return nil, false, diags
+	}
+
+	var exclusions, accounts []string
+	diags.Append(m.Exclusions.ElementsAs(ctx, &exclusions, false)...)
+	diags.Append(m.PrivilegedAccounts.ElementsAs(ctx, &accounts, false)...)
+	if diags.HasError() {
+		return nil, false, diags
+	}
+
+	var entries []baselineEntry
+	for _, rule := range preset.expand(exclusions, accounts) {
+		entries = append(entries, baselineEntry{name: rule.name, rule: rule.rule})
+	}
+
+	return entries, true, diags
+}
+
+// stateRules returns the rules of the state along with their ids.
+func (m auditLogBaselineResourceModel) stateRules(ctx context.Context) ([]baselineEntry, []int64, diag.Diagnostics) {
+	if m.Rules.IsNull() || m.Rules.IsUnknown() {
+		return nil, nil, nil
+	}
+
+	var models []auditLogBaselineRuleModel
+	diags := m.Rules.ElementsAs(ctx, &models, false)
+
+	var entries []baselineEntry
+	var ids []int64
+	for _, rule := range models {
+		id, err := strconv.ParseInt(rule.ID.ValueString(), 10, 64)
+		if err != nil {
+			diags.AddError(
+				"Error converting id to int",
+				fmt.Sprintf("Could not convert rule with id %s: %s", rule.ID.ValueString(), err.Error()),
+			)
+			return nil, nil, diags
+		}
+
+		entries = append(entries, baselineEntry{
+			name: rule.Name.ValueString(),
+			rule: AuditRule{
+				Username:  rule.Username.ValueString(),
+				Dbname:    rule.DbName.ValueString(),
+				Object:    rule.Object.ValueString(),
+				Operation: rule.Operation.ValueString(),
+				OpResult:  rule.OpResult.ValueString(),
+			},
+		})
+		ids = append(ids, id)
+	}
+
+	return entries, ids, diags
+}
+
+func baselineRuleModel(entry baselineEntry, id types.String) auditLogBaselineRuleModel {
+	return auditLogBaselineRuleModel{
+		Name:      types.StringValue(entry.name),
+		ID:        id,
+		Username:  types.StringValue(entry.rule.Username),
+		DbName:    types.StringValue(entry.rule.Dbname),
+		Object:    types.StringValue(entry.rule.Object),
+		Operation: types.StringValue(entry.rule.Operation),
+		OpResult:  types.StringValue(entry.rule.OpResult),
+	}
+}
+
+func baselineRulesValue(ctx context.Context, entries []baselineEntry, ids []int64) (types.List, diag.Diagnostics) {
+	models := []auditLogBaselineRuleModel{}
+	for i, entry := range entries {
+		models = append(models, baselineRuleModel(entry, types.StringValue(strconv.FormatInt(ids[i], 10))))
+	}
+
+	return types.ListValueFrom(ctx, auditLogBaselineRuleType, models)
+}
+
+func (r *auditLogBaselineResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(CloudSqlClientAndConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *sql.DB got %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !client.supportsEngine("mysql") {
+		resp.Diagnostics.AddError(
+			"Must use mysql engine for mysql types",
+			fmt.Sprintf("Configured engine is %q", client.engine),
+		)
+
+		return
+	}
+
+	r.client = client
+}
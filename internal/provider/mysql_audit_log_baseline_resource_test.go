@@ -0,0 +1,110 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"terraform-provider-cloudsql-auditlog/internal/cloudsqlfake"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccAuditLogBaselineResource(t *testing.T) {
+	server, providerConfig := newTestInstance(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckNoAuditRules(server),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_log_baseline" "test" {
+  preset              = "soc2"
+  privileged_accounts = ["root@%", "admin@%"]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_log_baseline.test", "id", "soc2"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_log_baseline.test", "version", "1"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_log_baseline.test", "rules.#", "3"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_log_baseline.test", "rules.0.name", "ddl_dcl"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_log_baseline.test", "rules.1.op_result", "U"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_log_baseline.test", "rules.2.username", "root@%,admin@%"),
+					testAccCheckRuleCount(server, 3),
+				),
+			},
+			{
+				// excluded rules are deleted, changed ones updated in place
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_log_baseline" "test" {
+  preset     = "soc2"
+  exclusions = ["failed_logins"]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_log_baseline.test", "rules.#", "2"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_log_baseline.test", "rules.1.name", "privileged_access"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_log_baseline.test", "rules.1.id", "3"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_log_baseline.test", "rules.1.username", "root@%"),
+					testAccCheckRuleCount(server, 2),
+				),
+			},
+			{
+				// rules changed outside of terraform are restored
+				PreConfig: func() {
+					_, err := server.DB().Exec("CALL mysql.cloudsql_update_audit_rule(?, ?, ?, ?, ?, ?, 1, @outval, @outmsg)",
+						1, "*", "*", "*", "ddl", "S")
+					if err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_log_baseline" "test" {
+  preset     = "soc2"
+  exclusions = ["failed_logins"]
+}
+`,
+				Check: func(_ *terraform.State) error {
+					want := cloudsqlfake.Rule{ID: 1, Username: "*", Dbname: "*", Object: "*", Operation: "ddl,dcl", OpResult: "B"}
+					if rule := server.Rules()[0]; rule != want {
+						return fmt.Errorf("expected rule %+v, got %+v", want, rule)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccAuditLogBaselineResourceInvalid(t *testing.T) {
+	_, providerConfig := newTestInstance(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_log_baseline" "test" {
+  preset = "iso27001"
+}
+`,
+				ExpectError: regexp.MustCompile(`allowed values: hipaa, pci-dss, soc2`),
+			},
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_log_baseline" "test" {
+  preset     = "hipaa"
+  exclusions = ["failed_statements"]
+}
+`,
+				ExpectError: regexp.MustCompile(`has no rule "failed_statements"`),
+			},
+		},
+	})
+}
@@ -0,0 +1,252 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"terraform-provider-cloudsql-auditlog/db"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &auditLogPluginDataSource{}
+	_ datasource.DataSourceWithConfigure = &auditLogPluginDataSource{}
+)
+
+// defaultAuditLogPluginName is the name cloudsql registers its audit plugin
+// under in information_schema.PLUGINS.
+const defaultAuditLogPluginName = "cloudsql_mysql_audit"
+
+// auditLogEnabledVariable is the database flag that turns auditing on.
+const auditLogEnabledVariable = "cloudsql_mysql_audit_log"
+
+const readAuditLogPlugin = `SELECT PLUGIN_STATUS, PLUGIN_VERSION FROM information_schema.PLUGINS WHERE PLUGIN_NAME = ?`
+
+// the audit plugin variables are prefixed with audit_log while the flag that
+// enables the plugin is exposed with the cloudsql prefix.
+var auditLogVariableQueries = []string{
+	"SHOW GLOBAL VARIABLES LIKE 'audit_log%'",
+	"SHOW GLOBAL VARIABLES LIKE 'cloudsql_mysql_audit%'",
+}
+
+// NewAuditLogPluginDataSource is a helper function to simplify the provider implementation.
+func NewAuditLogPluginDataSource() datasource.DataSource {
+	return &auditLogPluginDataSource{}
+}
+
+// auditLogPluginDataSource is the data source implementation.
+type auditLogPluginDataSource struct {
+	client CloudSqlClientAndConfig
+}
+
+// auditLogPluginDataSourceModel maps the data source schema data.
+type auditLogPluginDataSourceModel struct {
+	Instance       types.String `tfsdk:"instance"`
+	PluginName     types.String `tfsdk:"plugin_name"`
+	RequireEnabled types.Bool   `tfsdk:"require_enabled"`
+	Installed      types.Bool   `tfsdk:"installed"`
+	Status         types.String `tfsdk:"status"`
+	Version        types.String `tfsdk:"version"`
+	Enabled        types.Bool   `tfsdk:"enabled"`
+	Variables      types.Map    `tfsdk:"variables"`
+}
+
+// Metadata returns the data source type name.
+func (d *auditLogPluginDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_audit_log_plugin"
+}
+
+// Schema defines the schema for the data source.
+func (d *auditLogPluginDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"instance": schema.StringAttribute{
+				Optional: true,
+			},
+			"plugin_name": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+			},
+			"require_enabled": schema.BoolAttribute{
+				Optional: true,
+			},
+			"installed": schema.BoolAttribute{
+				Computed: true,
+			},
+			"status": schema.StringAttribute{
+				Computed: true,
+			},
+			"version": schema.StringAttribute{
+				Computed: true,
+			},
+			"enabled": schema.BoolAttribute{
+				Computed: true,
+			},
+			"variables": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *auditLogPluginDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state auditLogPluginDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.PluginName.IsNull() || state.PluginName.ValueString() == "" {
+		state.PluginName = types.StringValue(defaultAuditLogPluginName)
+	}
+
+	if d.client.unknown {
+		deferDataSourceRead(req, resp)
+		return
+	}
+
+	instance, err := d.client.connection(ctx, state.Instance)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+
+	conn, release, err := instance.tracedConn(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+	defer release()
+
+	var status, version string
+	err = conn.QueryRowContext(ctx, readAuditLogPlugin, state.PluginName.ValueString()).Scan(&status, &version)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		resp.Diagnostics.AddError(
+			"Unable to query audit log plugin",
+			err.Error(),
+		)
+		return
+	}
+
+	installed := err == nil
+	state.Installed = types.BoolValue(installed)
+	if installed {
+		state.Status = types.StringValue(status)
+		state.Version = types.StringValue(version)
+	} else {
+		state.Status = types.StringNull()
+		state.Version = types.StringNull()
+	}
+
+	variables := make(map[string]string)
+	for _, query := range auditLogVariableQueries {
+		err := readGlobalVariables(ctx, conn, query, variables)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to query audit log variables",
+				err.Error(),
+			)
+			return
+		}
+	}
+
+	state.Variables, diags = types.MapValueFrom(ctx, types.StringType, variables)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the flag isn't exposed by every cloudsql version, in which case an
+	// active plugin is as good as it gets
+	enabled := installed && strings.EqualFold(status, "ACTIVE")
+	if flag, ok := variables[auditLogEnabledVariable]; ok {
+		enabled = enabled && strings.EqualFold(flag, "ON")
+	}
+	state.Enabled = types.BoolValue(enabled)
+
+	if state.RequireEnabled.ValueBool() && !enabled {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("require_enabled"),
+			"Audit logging is disabled",
+			fmt.Sprintf("The %s plugin is not active or the %s flag is off, audit rules will not be applied.",
+				state.PluginName.ValueString(), auditLogEnabledVariable),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// readGlobalVariables adds the variables returned by a SHOW VARIABLES query
+// to vars.
+func readGlobalVariables(ctx context.Context, conn db.DBTX, query string, vars map[string]string) error {
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return err
+		}
+		vars[name] = value
+	}
+
+	return rows.Err()
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *auditLogPluginDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(CloudSqlClientAndConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *sql.DB got %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !client.supportsEngine("mysql") {
+		resp.Diagnostics.AddError(
+			"Must use mysql engine for mysql types",
+			fmt.Sprintf("Configured engine is %q", client.engine),
+		)
+
+		return
+	}
+
+	d.client = client
+}
@@ -0,0 +1,115 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// testReadUnknownProvider reads the data source with an empty configuration
+// while the provider configuration is unknown.
+func testReadUnknownProvider(t *testing.T, d datasource.DataSource, deferralAllowed bool) *datasource.ReadResponse {
+	t.Helper()
+	ctx := context.Background()
+
+	d.(datasource.DataSourceWithConfigure).Configure(ctx, datasource.ConfigureRequest{
+		ProviderData: CloudSqlClientAndConfig{engine: "mysql", unknown: true},
+	}, &datasource.ConfigureResponse{})
+
+	var schemaResp datasource.SchemaResponse
+	d.Schema(ctx, datasource.SchemaRequest{}, &schemaResp)
+
+	schemaType := schemaResp.Schema.Type().TerraformType(ctx)
+	values := make(map[string]tftypes.Value)
+	for name, attributeType := range schemaType.(tftypes.Object).AttributeTypes {
+		values[name] = tftypes.NewValue(attributeType, nil)
+	}
+
+	req := datasource.ReadRequest{
+		Config: tfsdk.Config{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(schemaType, values),
+		},
+	}
+	req.ClientCapabilities.DeferralAllowed = deferralAllowed
+
+	resp := &datasource.ReadResponse{
+		State: tfsdk.State{Schema: schemaResp.Schema},
+	}
+	d.Read(ctx, req, resp)
+
+	return resp
+}
+
+func TestAuditLogPluginDataSourceUnknownProvider(t *testing.T) {
+	resp := testReadUnknownProvider(t, NewAuditLogPluginDataSource(), true)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if resp.Deferred == nil || resp.Deferred.Reason != datasource.DeferredReasonProviderConfigUnknown {
+		t.Fatalf("expected the read to be deferred, got %+v", resp.Deferred)
+	}
+
+	resp = testReadUnknownProvider(t, NewAuditLogPluginDataSource(), false)
+	if !resp.Diagnostics.HasError() || resp.Diagnostics[0].Summary() != "Unknown provider configuration" {
+		t.Fatalf("expected the unknown provider configuration error, got %v", resp.Diagnostics)
+	}
+}
+
+func TestAccAuditLogPluginDataSource(t *testing.T) {
+	server, providerConfig := newTestInstance(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `data "cloudsql-auditlog_audit_log_plugin" "test" {}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_plugin.test", "plugin_name", "cloudsql_mysql_audit"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_plugin.test", "installed", "true"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_plugin.test", "status", "ACTIVE"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_plugin.test", "version", "1.0"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_plugin.test", "enabled", "true"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_plugin.test", "variables.cloudsql_mysql_audit_log", "ON"),
+				),
+			},
+			{
+				PreConfig: func() {
+					server.Variables["cloudsql_mysql_audit_log"] = "OFF"
+				},
+				Config: providerConfig + `data "cloudsql-auditlog_audit_log_plugin" "test" {}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_plugin.test", "installed", "true"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_plugin.test", "enabled", "false"),
+				),
+			},
+			{
+				Config: providerConfig + `
+data "cloudsql-auditlog_audit_log_plugin" "test" {
+  require_enabled = true
+}
+`,
+				ExpectError: regexp.MustCompile(`Audit logging is disabled`),
+			},
+			{
+				PreConfig: func() {
+					delete(server.Plugins, "cloudsql_mysql_audit")
+				},
+				Config: providerConfig + `data "cloudsql-auditlog_audit_log_plugin" "test" {}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_plugin.test", "installed", "false"),
+					resource.TestCheckNoResourceAttr("data.cloudsql-auditlog_audit_log_plugin.test", "status"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_plugin.test", "enabled", "false"),
+				),
+			},
+		},
+	})
+}
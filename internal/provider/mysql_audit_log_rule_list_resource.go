@@ -0,0 +1,224 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ list.ListResource              = &auditLogRuleListResource{}
+	_ list.ListResourceWithConfigure = &auditLogRuleListResource{}
+)
+
+// NewAuditLogRuleListResource is a helper function to simplify the provider implementation.
+func NewAuditLogRuleListResource() list.ListResource {
+	return &auditLogRuleListResource{}
+}
+
+// auditLogRuleListResource lists the audit rules for terraform query.
+type auditLogRuleListResource struct {
+	client CloudSqlClientAndConfig
+}
+
+// auditLogRuleListResourceModel maps the list block configuration. The
+// filters are matched against the rule fields with * and % wildcards.
+type auditLogRuleListResourceModel struct {
+	Instance  types.String `tfsdk:"instance"`
+	Username  types.String `tfsdk:"username"`
+	DbName    types.String `tfsdk:"dbname"`
+	Object    types.String `tfsdk:"object"`
+	Operation types.String `tfsdk:"operation"`
+	OpResult  types.String `tfsdk:"op_result"`
+}
+
+func (r *auditLogRuleListResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_audit_log_rule"
+}
+
+func (r *auditLogRuleListResource) ListResourceConfigSchema(_ context.Context, _ list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"instance": schema.StringAttribute{
+				Optional: true,
+			},
+			"username": schema.StringAttribute{
+				Optional: true,
+			},
+			"dbname": schema.StringAttribute{
+				Optional: true,
+			},
+			"object": schema.StringAttribute{
+				Optional: true,
+			},
+			"operation": schema.StringAttribute{
+				Optional: true,
+			},
+			"op_result": schema.StringAttribute{
+				Optional: true,
+			},
+		},
+	}
+}
+
+func (r *auditLogRuleListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	var config auditLogRuleListResourceModel
+	diags := req.Config.Get(ctx, &config)
+	if diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	if r.client.unknown {
+		diags.AddError(
+			"Unknown provider configuration",
+			"The provider connection details are only known during apply, audit rules can't be listed until then.",
+		)
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	// the rules are read right away since the connection is released
+	// before terraform consumes the results
+	rules, metadata, err := r.readRules(ctx, config.Instance)
+	if err != nil {
+		diags.AddError(
+			"Unable to query audit rules",
+			err.Error(),
+		)
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	stream.Results = func(push func(list.ListResult) bool) {
+		var count int64
+		for _, rule := range rules {
+			if req.Limit > 0 && count >= req.Limit {
+				return
+			}
+
+			if !config.matches(rule) {
+				continue
+			}
+			count++
+
+			if !push(r.listResult(ctx, req, config.Instance, rule, metadata)) {
+				return
+			}
+		}
+	}
+}
+
+// readRules returns the rules of the instance along with their metadata when
+// it is available.
+func (r *auditLogRuleListResource) readRules(ctx context.Context, instance types.String) ([]AuditRule, map[int64]AuditRuleMetadata, error) {
+	store, release, err := r.client.auditRuleStore(ctx, instance)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+
+	rules, err := store.List(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadata := make(map[int64]AuditRuleMetadata)
+	if ms, ok := metadataStore(ctx, store); ok {
+		all, err := ms.ListMetadata(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, m := range all {
+			metadata[m.RuleID] = m
+		}
+	}
+
+	return rules, metadata, nil
+}
+
+func (r *auditLogRuleListResource) listResult(ctx context.Context, req list.ListRequest, instance types.String, rule AuditRule, metadata map[int64]AuditRuleMetadata) list.ListResult {
+	result := req.NewListResult(ctx)
+	result.DisplayName = fmt.Sprintf("%s on %s.%s (%s, %s)", rule.Username, rule.Dbname, rule.Object, rule.Operation, rule.OpResult)
+
+	model := auditLogRuleResourceModel{
+		Instance:             instance,
+		Description:          types.StringNull(),
+		Owner:                types.StringNull(),
+		Ticket:               types.StringNull(),
+		ValidateUserExists:   types.BoolNull(),
+		ValidateObjectsExist: types.BoolNull(),
+	}
+	result.Diagnostics.Append(model.setAuditRule(ctx, rule)...)
+	if m, ok := metadata[rule.ID]; ok {
+		model.setAuditRuleMetadata(m)
+	}
+
+	result.Diagnostics.Append(result.Identity.Set(ctx, model.identity())...)
+
+	if req.IncludeResource {
+		result.Diagnostics.Append(result.Resource.Set(ctx, model)...)
+	}
+
+	return result
+}
+
+// matches reports whether the rule matches all the configured filters.
+func (m auditLogRuleListResourceModel) matches(rule AuditRule) bool {
+	filters := []struct {
+		filter types.String
+		value  string
+	}{
+		{m.Username, rule.Username},
+		{m.DbName, rule.Dbname},
+		{m.Object, rule.Object},
+		{m.Operation, rule.Operation},
+		{m.OpResult, rule.OpResult},
+	}
+
+	for _, f := range filters {
+		if !f.filter.IsNull() && !wildcardMatch(f.filter.ValueString(), f.value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *auditLogRuleListResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(CloudSqlClientAndConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected List Resource Configure Type",
+			fmt.Sprintf("Expected *sql.DB got %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !client.supportsEngine("mysql") {
+		resp.Diagnostics.AddError(
+			"Must use mysql engine for mysql types",
+			fmt.Sprintf("Configured engine is %q", client.engine),
+		)
+
+		return
+	}
+
+	r.client = client
+}
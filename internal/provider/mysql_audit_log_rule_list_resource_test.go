@@ -0,0 +1,141 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"terraform-provider-cloudsql-auditlog/internal/cloudsqlfake"
+
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// testListAuditLogRules runs the list resource against the fake instance
+// with the given filters and returns the results.
+func testListAuditLogRules(t *testing.T, filters map[string]string, includeResource bool, limit int64) []list.ListResult {
+	t.Helper()
+	ctx := context.Background()
+
+	endpoint := "fake-" + strings.ReplaceAll(t.Name(), "/", "-")
+	client := CloudSqlClientAndConfig{
+		engine:      "mysql",
+		connections: newInstanceConnections(map[string]connectionConfig{defaultInstance: {endpoint: endpoint, username: "root"}}),
+	}
+
+	r := &auditLogRuleListResource{client: client}
+
+	var schemaResp list.ListResourceSchemaResponse
+	r.ListResourceConfigSchema(ctx, list.ListResourceSchemaRequest{}, &schemaResp)
+
+	res := NewAuditLogRuleResource().(resource.ResourceWithIdentity)
+	var resourceSchema resource.SchemaResponse
+	res.Schema(ctx, resource.SchemaRequest{}, &resourceSchema)
+	var identitySchema resource.IdentitySchemaResponse
+	res.IdentitySchema(ctx, resource.IdentitySchemaRequest{}, &identitySchema)
+
+	values := make(map[string]tftypes.Value)
+	for name := range schemaResp.Schema.Attributes {
+		if v, ok := filters[name]; ok {
+			values[name] = tftypes.NewValue(tftypes.String, v)
+		} else {
+			values[name] = tftypes.NewValue(tftypes.String, nil)
+		}
+	}
+
+	req := list.ListRequest{
+		Config: tfsdk.Config{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), values),
+		},
+		IncludeResource:        includeResource,
+		Limit:                  limit,
+		ResourceSchema:         resourceSchema.Schema,
+		ResourceIdentitySchema: identitySchema.IdentitySchema,
+	}
+
+	var stream list.ListResultsStream
+	r.List(ctx, req, &stream)
+
+	var results []list.ListResult
+	for result := range stream.Results {
+		if result.Diagnostics.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", result.Diagnostics)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func TestAuditLogRuleListResource(t *testing.T) {
+	server, _ := newTestInstance(t)
+	server.AddRule(cloudsqlfake.Rule{Username: "app@%", Dbname: "app", Object: "*", Operation: "dml", OpResult: "B"})
+	server.AddRule(cloudsqlfake.Rule{Username: "admin@%", Dbname: "*", Object: "*", Operation: "ddl", OpResult: "S"})
+	server.AddRule(cloudsqlfake.Rule{Username: "app@10.%", Dbname: "reports", Object: "*", Operation: "select", OpResult: "E"})
+
+	results := testListAuditLogRules(t, map[string]string{"username": "app@*"}, true, 0)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	var identity auditLogRuleIdentityModel
+	if diags := results[0].Identity.Get(context.Background(), &identity); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if identity.Username.ValueString() != "app@%" || identity.DbName.ValueString() != "app" || !identity.Instance.IsNull() {
+		t.Fatalf("unexpected identity %+v", identity)
+	}
+
+	var state auditLogRuleResourceModel
+	if diags := results[1].Resource.Get(context.Background(), &state); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if state.ID.ValueString() != "3" || state.OpResult.ValueString() != "E" {
+		t.Fatalf("unexpected resource %+v", state)
+	}
+}
+
+func TestAuditLogRuleListResourceLimit(t *testing.T) {
+	server, _ := newTestInstance(t)
+	for _, user := range []string{"a@%", "b@%", "c@%"} {
+		server.AddRule(cloudsqlfake.Rule{Username: user, Dbname: "*", Object: "*", Operation: "*", OpResult: "B"})
+	}
+
+	results := testListAuditLogRules(t, nil, false, 2)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	if !results[0].Resource.Raw.IsNull() {
+		t.Fatal("expected the resource not to be included")
+	}
+}
+
+func TestAuditLogRuleListResourceFilters(t *testing.T) {
+	rule := AuditRule{Username: "app@%", Dbname: "app", Object: "orders", Operation: "dml", OpResult: "B"}
+
+	tests := map[string]struct {
+		filters auditLogRuleListResourceModel
+		want    bool
+	}{
+		"none":     {auditLogRuleListResourceModel{}, true},
+		"exact":    {auditLogRuleListResourceModel{Object: types.StringValue("orders")}, true},
+		"wildcard": {auditLogRuleListResourceModel{Username: types.StringValue("*@%"), DbName: types.StringValue("a*")}, true},
+		"mismatch": {auditLogRuleListResourceModel{OpResult: types.StringValue("S")}, false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := test.filters.matches(rule); got != test.want {
+				t.Fatalf("expected %t, got %t", test.want, got)
+			}
+		})
+	}
+}
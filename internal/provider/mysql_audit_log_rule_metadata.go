@@ -0,0 +1,107 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"terraform-provider-cloudsql-auditlog/db"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// createAuditRuleMetadataTable mirrors the tf_audit_rule_metadata definition
+// in schema.sql, the provider creates it on demand in the mysql schema.
+const createAuditRuleMetadataTable = "CREATE TABLE IF NOT EXISTS `tf_audit_rule_metadata` (" +
+	"`rule_id` bigint NOT NULL, " +
+	"`description` varchar(2048) COLLATE utf8mb4_bin DEFAULT NULL, " +
+	"`owner` varchar(255) COLLATE utf8mb4_bin DEFAULT NULL, " +
+	"`ticket` varchar(255) COLLATE utf8mb4_bin DEFAULT NULL, " +
+	"PRIMARY KEY (`rule_id`)" +
+	") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin"
+
+const probeAuditRuleMetadataTable = "SELECT COUNT(*) FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'tf_audit_rule_metadata'"
+
+// auditRuleMetadataTable keeps track of whether the provider-managed metadata
+// side table can be used on the configured instance. When it can't (e.g., the
+// user isn't allowed to create tables in the mysql schema) the rule metadata
+// is only tracked in the terraform state.
+type auditRuleMetadataTable struct {
+	mu        sync.Mutex
+	available bool
+}
+
+// Available reports whether the metadata table exists, with create it is
+// created first when it doesn't. Only a table that was found is remembered,
+// failures are checked again the next time as the table may be created in
+// the meantime.
+func (t *auditRuleMetadataTable) Available(ctx context.Context, conn db.DBTX, create bool) bool {
+	if t == nil || conn == nil {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.available {
+		return true
+	}
+
+	if create {
+		if _, err := conn.ExecContext(ctx, createAuditRuleMetadataTable); err == nil {
+			t.available = true
+			return true
+		}
+	}
+
+	// the table may have been created by someone else even if we aren't
+	// allowed to create it ourselves
+	var tables int
+	if err := conn.QueryRowContext(ctx, probeAuditRuleMetadataTable).Scan(&tables); err != nil {
+		return false
+	}
+	t.available = tables > 0
+
+	return t.available
+}
+
+// hasMetadata reports whether any of the metadata attributes are set.
+func (m auditLogRuleResourceModel) hasMetadata() bool {
+	return !m.Description.IsNull() || !m.Owner.IsNull() || !m.Ticket.IsNull()
+}
+
+// auditRuleMetadata returns the metadata of the model for the given rule.
+func (m auditLogRuleResourceModel) auditRuleMetadata(ruleID int64) AuditRuleMetadata {
+	return AuditRuleMetadata{
+		RuleID:      ruleID,
+		Description: nullStringFromValue(m.Description),
+		Owner:       nullStringFromValue(m.Owner),
+		Ticket:      nullStringFromValue(m.Ticket),
+	}
+}
+
+// setAuditRuleMetadata sets the metadata attributes of the model.
+func (m *auditLogRuleResourceModel) setAuditRuleMetadata(metadata AuditRuleMetadata) {
+	m.Description = valueFromNullString(metadata.Description)
+	m.Owner = valueFromNullString(metadata.Owner)
+	m.Ticket = valueFromNullString(metadata.Ticket)
+}
+
+func nullStringFromValue(v types.String) sql.NullString {
+	if v.IsNull() || v.IsUnknown() {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: v.ValueString(), Valid: true}
+}
+
+func valueFromNullString(s sql.NullString) types.String {
+	if !s.Valid {
+		return types.StringNull()
+	}
+
+	return types.StringValue(s.String)
+}
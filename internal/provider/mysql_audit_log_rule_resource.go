@@ -5,14 +5,15 @@ package provider
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
 	"strconv"
-	"terraform-provider-cloudsql-auditlog/db"
+	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 
@@ -25,6 +26,8 @@ var (
 	_ resource.Resource                = &auditLogRuleResource{}
 	_ resource.ResourceWithConfigure   = &auditLogRuleResource{}
 	_ resource.ResourceWithImportState = &auditLogRuleResource{}
+	_ resource.ResourceWithModifyPlan  = &auditLogRuleResource{}
+	_ resource.ResourceWithIdentity    = &auditLogRuleResource{}
 )
 
 func NewAuditLogRuleResource() resource.Resource {
@@ -37,16 +40,64 @@ type auditLogRuleResource struct {
 
 type auditLogRuleResourceModel struct {
 	ID        types.String `tfsdk:"id"`
+	Instance  types.String `tfsdk:"instance"`
 	Username  types.String `tfsdk:"username"`
 	DbName    types.String `tfsdk:"dbname"`
 	Object    types.String `tfsdk:"object"`
 	Operation types.String `tfsdk:"operation"`
 	OpResult  types.String `tfsdk:"op_result"`
+
+	ExpandedOperations types.List `tfsdk:"expanded_operations"`
+
+	Description types.String `tfsdk:"description"`
+	Owner       types.String `tfsdk:"owner"`
+	Ticket      types.String `tfsdk:"ticket"`
+
+	ValidateUserExists   types.Bool `tfsdk:"validate_user_exists"`
+	ValidateObjectsExist types.Bool `tfsdk:"validate_objects_exist"`
 	// LastUpdated types.String `tfsdk:"last_updated"`
 }
 
+// auditLogRuleIdentityModel maps the resource identity schema data.
+type auditLogRuleIdentityModel struct {
+	Instance  types.String `tfsdk:"instance"`
+	Username  types.String `tfsdk:"username"`
+	DbName    types.String `tfsdk:"dbname"`
+	Object    types.String `tfsdk:"object"`
+	Operation types.String `tfsdk:"operation"`
+	OpResult  types.String `tfsdk:"op_result"`
+}
+
 func (r *auditLogRuleResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_audit_log_rule"
+
+	// the identity is the rule itself, it changes with every in-place update
+	resp.ResourceBehavior.MutableIdentity = true
+}
+
+func (r *auditLogRuleResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"instance": identityschema.StringAttribute{
+				OptionalForImport: true,
+			},
+			"username": identityschema.StringAttribute{
+				RequiredForImport: true,
+			},
+			"dbname": identityschema.StringAttribute{
+				RequiredForImport: true,
+			},
+			"object": identityschema.StringAttribute{
+				RequiredForImport: true,
+			},
+			"operation": identityschema.StringAttribute{
+				RequiredForImport: true,
+			},
+			"op_result": identityschema.StringAttribute{
+				RequiredForImport: true,
+			},
+		},
+	}
 }
 
 func (r *auditLogRuleResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
@@ -58,6 +109,12 @@ func (r *auditLogRuleResource) Schema(_ context.Context, _ resource.SchemaReques
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"instance": schema.StringAttribute{
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
 			"username": schema.StringAttribute{
 				Required: true,
 			},
@@ -73,6 +130,25 @@ func (r *auditLogRuleResource) Schema(_ context.Context, _ resource.SchemaReques
 			"op_result": schema.StringAttribute{
 				Required: true,
 			},
+			"expanded_operations": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+			"description": schema.StringAttribute{
+				Optional: true,
+			},
+			"owner": schema.StringAttribute{
+				Optional: true,
+			},
+			"ticket": schema.StringAttribute{
+				Optional: true,
+			},
+			"validate_user_exists": schema.BoolAttribute{
+				Optional: true,
+			},
+			"validate_objects_exist": schema.BoolAttribute{
+				Optional: true,
+			},
 			// "last_updated": schema.StringAttribute{
 			// 	Computed: true,
 			// },
@@ -80,6 +156,55 @@ func (r *auditLogRuleResource) Schema(_ context.Context, _ resource.SchemaReques
 	}
 }
 
+func (r *auditLogRuleResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check when the resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan auditLogRuleResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the account or objects might be created by the same apply so only
+	// warn here, create and update fail if they still don't exist by then.
+	// Without a known provider configuration there's nothing to check yet.
+	if !r.client.unknown {
+		resp.Diagnostics.Append(r.validateReferences(ctx, plan, diag.SeverityWarning)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	plan.ExpandedOperations, diags = expandedOperationsValue(ctx, plan.Operation)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the guardrails can only be checked once every field is known, which
+	// is at the latest during the apply plan
+	if r.client.guardrails != nil && !plan.Username.IsUnknown() && !plan.DbName.IsUnknown() &&
+		!plan.Object.IsUnknown() && !plan.Operation.IsUnknown() && !plan.OpResult.IsUnknown() {
+		for _, violation := range r.client.guardrails.check(plan.auditRule()) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(violation.attribute),
+				"Audit rule violates the provider guardrails",
+				violation.message,
+			)
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	diags = resp.Plan.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
 func (r *auditLogRuleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	// retrieve values from plan
 	var plan auditLogRuleResourceModel
@@ -89,22 +214,29 @@ func (r *auditLogRuleResource) Create(ctx context.Context, req resource.CreateRe
 		return
 	}
 
-	q := db.New(r.client.client)
-	ruleIdCheck, err := q.ReadAuditRuleIDAfterCreate(ctx,
-		db.ReadAuditRuleIDAfterCreateParams{
-			Username:  plan.Username.ValueString(),
-			Dbname:    plan.DbName.ValueString(),
-			Object:    plan.Object.ValueString(),
-			Operation: plan.Operation.ValueString(),
-			OpResult:  plan.OpResult.ValueString(),
-		})
+	resp.Diagnostics.Append(r.validateReferences(ctx, plan, diag.SeverityError)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	store, release, err := r.client.auditRuleStore(ctx, plan.Instance)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+	defer release()
+
+	ruleIdCheck, err := store.Find(ctx, plan.auditRule())
 	if err == nil {
 		resp.Diagnostics.AddError(
 			"Rule already exists",
 			fmt.Errorf("existing ID: %d", ruleIdCheck).Error(),
 		)
 		return
-	} else if !errors.Is(err, sql.ErrNoRows) {
+	} else if !errors.Is(err, ErrAuditRuleNotFound) {
 		resp.Diagnostics.AddError(
 			"Unable to check rule existence",
 			err.Error(),
@@ -112,13 +244,7 @@ func (r *auditLogRuleResource) Create(ctx context.Context, req resource.CreateRe
 		return
 	}
 
-	err = q.CreateAuditRule(ctx, db.CreateAuditRuleParams{
-		Username:  plan.Username.ValueString(),
-		Dbname:    plan.DbName.ValueString(),
-		Object:    plan.Object.ValueString(),
-		Operation: plan.Operation.ValueString(),
-		OpResult:  plan.OpResult.ValueString(),
-	})
+	ruleID, err := store.Create(ctx, plan.auditRule())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to call audit rule create",
@@ -127,29 +253,31 @@ func (r *auditLogRuleResource) Create(ctx context.Context, req resource.CreateRe
 		return
 	}
 
-	ruleID, err := q.ReadAuditRuleIDAfterCreate(ctx,
-		db.ReadAuditRuleIDAfterCreateParams{
-			Username:  plan.Username.ValueString(),
-			Dbname:    plan.DbName.ValueString(),
-			Object:    plan.Object.ValueString(),
-			Operation: plan.Operation.ValueString(),
-			OpResult:  plan.OpResult.ValueString(),
-		})
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Unable to call read after create",
-			err.Error(),
-		)
-		return
+	plan.ID = types.StringValue(strconv.FormatInt(ruleID, 10))
+
+	// the metadata table is only created once a rule carries metadata
+	if plan.hasMetadata() {
+		if metadata, ok := writableMetadataStore(ctx, store); ok {
+			err = metadata.SetMetadata(ctx, plan.auditRuleMetadata(ruleID))
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Unable to store audit rule metadata",
+					err.Error(),
+				)
+				return
+			}
+		}
 	}
 
-	plan.ID = types.StringValue(strconv.FormatInt(ruleID, 10))
 	// plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	diags = resp.Identity.Set(ctx, plan.identity())
+	resp.Diagnostics.Append(diags...)
 }
 
 func (r *auditLogRuleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -160,8 +288,31 @@ func (r *auditLogRuleResource) Read(ctx context.Context, req resource.ReadReques
 		return
 	}
 
-	q := db.New(r.client.client)
-	ruleID, err := strconv.Atoi(state.ID.ValueString())
+	// keep the identity in sync with the state even when the rule can't be
+	// refreshed, e.g., for states written before identities were supported
+	diags = resp.Identity.Set(ctx, state.identity())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the rule can't be refreshed until the provider configuration is
+	// known, keep the current state until then
+	if r.client.unknown {
+		return
+	}
+
+	store, release, err := r.client.auditRuleStore(ctx, state.Instance)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+	defer release()
+
+	ruleID, err := strconv.ParseInt(state.ID.ValueString(), 10, 64)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error converting id to int",
@@ -170,30 +321,49 @@ func (r *auditLogRuleResource) Read(ctx context.Context, req resource.ReadReques
 		return
 	}
 
-	rule, err := q.ReadAuditLogRuleByID(ctx, int64(ruleID))
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+	rule, err := store.Get(ctx, ruleID)
+	if err != nil && !errors.Is(err, ErrAuditRuleNotFound) {
 		resp.Diagnostics.AddError(
 			"Error reading audit log rule",
 			fmt.Sprintf("Could not read rule with id %s: %s", state.ID.ValueString(), err.Error()),
 		)
 		return
-	} else if err != nil && errors.Is(err, sql.ErrNoRows) {
+	} else if err != nil {
 		// Resource no longer exists, clear the state
 		resp.State.RemoveResource(ctx) // This is the key!
 		return
 	}
 
-	state.Username = types.StringValue(rule.Username)
-	state.DbName = types.StringValue(rule.Dbname)
-	state.Object = types.StringValue(rule.Object)
-	state.OpResult = types.StringValue(rule.OpResult)
-	state.Operation = types.StringValue(rule.Operation)
+	resp.Diagnostics.Append(state.setAuditRule(ctx, rule)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// without the side table the metadata only lives in the state, as it
+	// does for rules created before the table existed
+	if metadata, ok := metadataStore(ctx, store); ok {
+		m, err := metadata.GetMetadata(ctx, rule.ID)
+		if errors.Is(err, ErrAuditRuleMetadataNotFound) {
+			m = state.auditRuleMetadata(rule.ID)
+		} else if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading audit log rule metadata",
+				fmt.Sprintf("Could not read metadata for rule with id %s: %s", state.ID.ValueString(), err.Error()),
+			)
+			return
+		}
+
+		state.setAuditRuleMetadata(m)
+	}
 
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	diags = resp.Identity.Set(ctx, state.identity())
+	resp.Diagnostics.Append(diags...)
 }
 
 func (r *auditLogRuleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -205,15 +375,34 @@ func (r *auditLogRuleResource) Update(ctx context.Context, req resource.UpdateRe
 		return
 	}
 
-	q := db.New(r.client.client)
-	err := q.UpdatedAuditRuleByID(ctx, db.UpdatedAuditRuleByIDParams{
-		ID:        plan.ID.ValueString(),
-		Username:  plan.Username.ValueString(),
-		Dbname:    plan.DbName.ValueString(),
-		Object:    plan.Object.ValueString(),
-		Operation: plan.Operation.ValueString(),
-		OpResult:  plan.OpResult.ValueString(),
-	})
+	resp.Diagnostics.Append(r.validateReferences(ctx, plan, diag.SeverityError)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	store, release, err := r.client.auditRuleStore(ctx, plan.Instance)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+	defer release()
+
+	ruleID, err := strconv.ParseInt(plan.ID.ValueString(), 10, 64)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error converting id to int",
+			fmt.Sprintf("Could not convert rule with id %s: %s", plan.ID.ValueString(), err.Error()),
+		)
+		return
+	}
+
+	rule := plan.auditRule()
+	rule.ID = ruleID
+
+	err = store.Update(ctx, rule)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to call audit rule update",
@@ -222,6 +411,21 @@ func (r *auditLogRuleResource) Update(ctx context.Context, req resource.UpdateRe
 		return
 	}
 
+	if plan.hasMetadata() {
+		if metadata, ok := writableMetadataStore(ctx, store); ok {
+			err = metadata.SetMetadata(ctx, plan.auditRuleMetadata(ruleID))
+		}
+	} else if metadata, ok := metadataStore(ctx, store); ok {
+		err = metadata.DeleteMetadata(ctx, ruleID)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to store audit rule metadata",
+			err.Error(),
+		)
+		return
+	}
+
 	// plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
 
 	diags = resp.State.Set(ctx, plan)
@@ -229,6 +433,9 @@ func (r *auditLogRuleResource) Update(ctx context.Context, req resource.UpdateRe
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	diags = resp.Identity.Set(ctx, plan.identity())
+	resp.Diagnostics.Append(diags...)
 }
 
 func (r *auditLogRuleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -239,8 +446,26 @@ func (r *auditLogRuleResource) Delete(ctx context.Context, req resource.DeleteRe
 		return
 	}
 
-	q := db.New(r.client.client)
-	err := q.DeleteAuditRuleByID(ctx, state.ID.ValueString())
+	store, release, err := r.client.auditRuleStore(ctx, state.Instance)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+	defer release()
+
+	ruleID, err := strconv.ParseInt(state.ID.ValueString(), 10, 64)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error converting id to int",
+			fmt.Sprintf("Could not convert rule with id %s: %s", state.ID.ValueString(), err.Error()),
+		)
+		return
+	}
+
+	err = store.Delete(ctx, ruleID)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to call audit rule delete",
@@ -248,6 +473,155 @@ func (r *auditLogRuleResource) Delete(ctx context.Context, req resource.DeleteRe
 		)
 		return
 	}
+
+	if metadata, ok := metadataStore(ctx, store); ok {
+		err = metadata.DeleteMetadata(ctx, ruleID)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to delete audit rule metadata",
+				err.Error(),
+			)
+			return
+		}
+	}
+}
+
+// setAuditRule sets the id and the rule fields of the model.
+func (m *auditLogRuleResourceModel) setAuditRule(ctx context.Context, rule AuditRule) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	m.ID = types.StringValue(strconv.FormatInt(rule.ID, 10))
+	m.Username = types.StringValue(rule.Username)
+	m.DbName = types.StringValue(rule.Dbname)
+	m.Object = types.StringValue(rule.Object)
+	m.OpResult = types.StringValue(rule.OpResult)
+	m.Operation = types.StringValue(rule.Operation)
+
+	m.ExpandedOperations, diags = expandedOperationsValue(ctx, m.Operation)
+
+	return diags
+}
+
+// identity returns the resource identity of the rule described by the model.
+func (m auditLogRuleResourceModel) identity() auditLogRuleIdentityModel {
+	return auditLogRuleIdentityModel{
+		Instance:  m.Instance,
+		Username:  m.Username,
+		DbName:    m.DbName,
+		Object:    m.Object,
+		Operation: m.Operation,
+		OpResult:  m.OpResult,
+	}
+}
+
+// auditRule returns the rule described by the model, without its id.
+func (m auditLogRuleResourceModel) auditRule() AuditRule {
+	return AuditRule{
+		Username:  m.Username.ValueString(),
+		Dbname:    m.DbName.ValueString(),
+		Object:    m.Object.ValueString(),
+		Operation: m.Operation.ValueString(),
+		OpResult:  m.OpResult.ValueString(),
+	}
+}
+
+// validateReferences runs the opt-in existence checks for the accounts,
+// databases and objects the rule refers to.
+func (r *auditLogRuleResource) validateReferences(ctx context.Context, plan auditLogRuleResourceModel, severity diag.Severity) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	diags.Append(r.validateUserExists(ctx, plan, severity)...)
+	diags.Append(r.validateObjectsExist(ctx, plan, severity)...)
+
+	return diags
+}
+
+// validateUserExists checks, when requested, that the rule username matches
+// at least one account in mysql.user and reports it with the given severity.
+func (r *auditLogRuleResource) validateUserExists(ctx context.Context, plan auditLogRuleResourceModel, severity diag.Severity) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if !plan.ValidateUserExists.ValueBool() || plan.Username.IsUnknown() || plan.Instance.IsUnknown() {
+		return diags
+	}
+
+	conn, err := r.client.connection(ctx, plan.Instance)
+	if err != nil {
+		diags.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return diags
+	}
+
+	accounts, err := readMySQLAccounts(ctx, conn.db)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("validate_user_exists"),
+			"Unable to query mysql users",
+			err.Error(),
+		)
+		return diags
+	}
+
+	if len(matchingAccounts(plan.Username.ValueString(), accounts)) > 0 {
+		return diags
+	}
+
+	summary := "Audited user does not exist"
+	detail := fmt.Sprintf("No account in mysql.user matches %q, the rule would not audit anything.", plan.Username.ValueString())
+	if severity == diag.SeverityError {
+		diags.AddAttributeError(path.Root("username"), summary, detail)
+	} else {
+		diags.AddAttributeWarning(path.Root("username"), summary, detail)
+	}
+
+	return diags
+}
+
+// validateObjectsExist checks, when requested, that the concrete databases
+// and objects of the rule exist and reports missing ones with the given
+// severity.
+func (r *auditLogRuleResource) validateObjectsExist(ctx context.Context, plan auditLogRuleResourceModel, severity diag.Severity) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if !plan.ValidateObjectsExist.ValueBool() || plan.DbName.IsUnknown() || plan.Object.IsUnknown() || plan.Instance.IsUnknown() {
+		return diags
+	}
+
+	conn, err := r.client.connection(ctx, plan.Instance)
+	if err != nil {
+		diags.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return diags
+	}
+
+	catalog, err := readSchemaCatalog(ctx, conn.db)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("validate_objects_exist"),
+			"Unable to query databases and objects",
+			err.Error(),
+		)
+		return diags
+	}
+
+	missing := catalog.missing(plan.DbName.ValueString(), plan.Object.ValueString())
+	if len(missing) == 0 {
+		return diags
+	}
+
+	summary := "Audited database or object does not exist"
+	detail := fmt.Sprintf("The rule refers to databases or objects that don't exist: %s.", strings.Join(missing, ", "))
+	if severity == diag.SeverityError {
+		diags.AddAttributeError(path.Root("object"), summary, detail)
+	} else {
+		diags.AddAttributeWarning(path.Root("object"), summary, detail)
+	}
+
+	return diags
 }
 
 func (r *auditLogRuleResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
@@ -267,7 +641,7 @@ func (r *auditLogRuleResource) Configure(_ context.Context, req resource.Configu
 		return
 	}
 
-	if client.engine != "mysql" {
+	if !client.supportsEngine("mysql") {
 		resp.Diagnostics.AddError(
 			"Must use mysql engine for mysql types",
 			fmt.Sprintf("Configured engine is %q", client.engine),
@@ -279,6 +653,80 @@ func (r *auditLogRuleResource) Configure(_ context.Context, req resource.Configu
 	r.client = client
 }
 
+// ImportState accepts either the rule id, <instance>/<id> for rules on one
+// of the provider instances, or the resource identity. Rules imported by
+// identity are looked up by their fields since the id differs between
+// instances, e.g., after a clone.
 func (r *auditLogRuleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	if req.ID == "" {
+		r.importStateByIdentity(ctx, req, resp)
+		return
+	}
+
+	instance, id, found := cutLast(req.ID, "/")
+	if !found {
+		resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("instance"), instance)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
+}
+
+func (r *auditLogRuleResource) importStateByIdentity(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	var identity auditLogRuleIdentityModel
+	diags := req.Identity.Get(ctx, &identity)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.client.unknown {
+		resp.Diagnostics.AddError(
+			"Unknown provider configuration",
+			"The provider connection details are only known during apply, audit rules can't be imported by identity until then.",
+		)
+		return
+	}
+
+	store, release, err := r.client.auditRuleStore(ctx, identity.Instance)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+	defer release()
+
+	model := auditLogRuleResourceModel{
+		Instance:  identity.Instance,
+		Username:  identity.Username,
+		DbName:    identity.DbName,
+		Object:    identity.Object,
+		Operation: identity.Operation,
+		OpResult:  identity.OpResult,
+	}
+
+	id, err := store.Find(ctx, model.auditRule())
+	if errors.Is(err, ErrAuditRuleNotFound) {
+		resp.Diagnostics.AddError(
+			"Audit log rule not found",
+			fmt.Sprintf("No audit rule matches username %q, dbname %q, object %q, operation %q and op_result %q.",
+				identity.Username.ValueString(), identity.DbName.ValueString(), identity.Object.ValueString(),
+				identity.Operation.ValueString(), identity.OpResult.ValueString()),
+		)
+		return
+	} else if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading audit log rule",
+			err.Error(),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), strconv.FormatInt(id, 10))...)
+	if !identity.Instance.IsNull() {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("instance"), identity.Instance)...)
+	}
 }
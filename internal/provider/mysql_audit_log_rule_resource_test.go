@@ -0,0 +1,382 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"terraform-provider-cloudsql-auditlog/internal/cloudsqlfake"
+
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccAuditLogRuleResource(t *testing.T) {
+	server, providerConfig := newTestInstance(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckNoAuditRules(server),
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_log_rule" "test" {
+  username    = "user@%"
+  dbname      = "*"
+  object      = "*"
+  operation   = "dml"
+  op_result   = "B"
+  description = "audit all writes"
+  ticket      = "SEC-1"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_log_rule.test", "id", "1"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_log_rule.test", "expanded_operations.#", "5"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_log_rule.test", "expanded_operations.0", "insert"),
+					testAccCheckActiveAuditRule(server, cloudsqlfake.Rule{
+						ID:        1,
+						Username:  "user@%",
+						Dbname:    "*",
+						Object:    "*",
+						Operation: "dml",
+						OpResult:  "B",
+					}),
+					testAccCheckAuditRuleMetadata(server, 1, true),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "cloudsql-auditlog_audit_log_rule.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// Update and Read testing
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_log_rule" "test" {
+  username  = "user@%"
+  dbname    = "app"
+  object    = "*"
+  operation = "*"
+  op_result = "S"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_log_rule.test", "id", "1"),
+					resource.TestCheckNoResourceAttr("cloudsql-auditlog_audit_log_rule.test", "description"),
+					testAccCheckActiveAuditRule(server, cloudsqlfake.Rule{
+						ID:        1,
+						Username:  "user@%",
+						Dbname:    "app",
+						Object:    "*",
+						Operation: "*",
+						OpResult:  "S",
+					}),
+					testAccCheckAuditRuleMetadata(server, 1, false),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccAuditLogRuleResourceIdentity(t *testing.T) {
+	_, providerConfig := newTestInstance(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_12_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_log_rule" "test" {
+  username  = "user@%"
+  dbname    = "app"
+  object    = "*"
+  operation = "ddl"
+  op_result = "E"
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectIdentity("cloudsql-auditlog_audit_log_rule.test", map[string]knownvalue.Check{
+						"instance":  knownvalue.Null(),
+						"username":  knownvalue.StringExact("user@%"),
+						"dbname":    knownvalue.StringExact("app"),
+						"object":    knownvalue.StringExact("*"),
+						"operation": knownvalue.StringExact("ddl"),
+						"op_result": knownvalue.StringExact("E"),
+					}),
+				},
+			},
+			{
+				ResourceName:    "cloudsql-auditlog_audit_log_rule.test",
+				ImportState:     true,
+				ImportStateKind: resource.ImportBlockWithResourceIdentity,
+			},
+		},
+	})
+}
+
+func TestAuditLogRuleResourceImportStateByIdentity(t *testing.T) {
+	ctx := context.Background()
+
+	server, _ := newTestInstance(t)
+	server.AddRule(cloudsqlfake.Rule{Username: "other@%", Dbname: "*", Object: "*", Operation: "*", OpResult: "B"})
+	server.AddRule(cloudsqlfake.Rule{Username: "user@%", Dbname: "app", Object: "*", Operation: "ddl", OpResult: "E"})
+
+	r := &auditLogRuleResource{client: CloudSqlClientAndConfig{
+		engine:      "mysql",
+		connections: newInstanceConnections(map[string]connectionConfig{defaultInstance: {endpoint: "fake-" + t.Name(), username: "root"}}),
+	}}
+
+	var schema fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schema)
+	var identitySchema fwresource.IdentitySchemaResponse
+	r.IdentitySchema(ctx, fwresource.IdentitySchemaRequest{}, &identitySchema)
+
+	identity := func(username string) *tfsdk.ResourceIdentity {
+		return &tfsdk.ResourceIdentity{
+			Schema: identitySchema.IdentitySchema,
+			Raw: tftypes.NewValue(identitySchema.IdentitySchema.Type().TerraformType(ctx), map[string]tftypes.Value{
+				"instance":  tftypes.NewValue(tftypes.String, nil),
+				"username":  tftypes.NewValue(tftypes.String, username),
+				"dbname":    tftypes.NewValue(tftypes.String, "app"),
+				"object":    tftypes.NewValue(tftypes.String, "*"),
+				"operation": tftypes.NewValue(tftypes.String, "ddl"),
+				"op_result": tftypes.NewValue(tftypes.String, "E"),
+			}),
+		}
+	}
+
+	importState := func(username string) fwresource.ImportStateResponse {
+		resp := fwresource.ImportStateResponse{
+			State: tfsdk.State{
+				Schema: schema.Schema,
+				Raw:    tftypes.NewValue(schema.Schema.Type().TerraformType(ctx), nil),
+			},
+			Identity: identity(username),
+		}
+		r.ImportState(ctx, fwresource.ImportStateRequest{Identity: identity(username)}, &resp)
+
+		return resp
+	}
+
+	resp := importState("user@%")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var state auditLogRuleResourceModel
+	resp.Diagnostics.Append(resp.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() || state.ID.ValueString() != "2" {
+		t.Fatalf("expected rule 2 to be imported, got %q: %v", state.ID.ValueString(), resp.Diagnostics)
+	}
+
+	resp = importState("missing@%")
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected importing a missing rule to fail")
+	}
+}
+
+func TestAccAuditLogRuleResourceWithoutMetadataTable(t *testing.T) {
+	server, providerConfig := newTestInstance(t)
+	server.DenyCreateTable = true
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckNoAuditRules(server),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_log_rule" "test" {
+  username    = "*"
+  dbname      = "*"
+  object      = "*"
+  operation   = "*"
+  op_result   = "E"
+  description = "kept in the state only"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_log_rule.test", "description", "kept in the state only"),
+					testAccCheckAuditRuleMetadata(server, 1, false),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAuditLogRuleResourceMetadataTableOnWrite(t *testing.T) {
+	server, providerConfig := newTestInstance(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckNoAuditRules(server),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_log_rule" "test" {
+  username  = "user@%"
+  dbname    = "*"
+  object    = "*"
+  operation = "ddl"
+  op_result = "B"
+}
+
+data "cloudsql-auditlog_audit_log_rules" "test" {
+  depends_on = [cloudsql-auditlog_audit_log_rule.test]
+}
+`,
+				Check: func(_ *terraform.State) error {
+					if server.MetadataTableExists() {
+						return fmt.Errorf("expected the metadata table not to be created without metadata")
+					}
+					return nil
+				},
+			},
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_log_rule" "test" {
+  username  = "user@%"
+  dbname    = "*"
+  object    = "*"
+  operation = "ddl"
+  op_result = "B"
+  owner     = "security"
+}
+`,
+				Check: testAccCheckAuditRuleMetadata(server, 1, true),
+			},
+		},
+	})
+}
+
+func TestAccAuditLogRuleResourceMetadataOnlyInState(t *testing.T) {
+	server, providerConfig := newTestInstance(t)
+	server.DenyCreateTable = true
+
+	config := providerConfig + `
+resource "cloudsql-auditlog_audit_log_rule" "before" {
+  username  = "user@%"
+  dbname    = "*"
+  object    = "*"
+  operation = "ddl"
+  op_result = "B"
+  owner     = "security"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckNoAuditRules(server),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_log_rule.before", "owner", "security"),
+					testAccCheckAuditRuleMetadata(server, 1, false),
+				),
+			},
+			{
+				// the table created for the second rule has no row for the
+				// first one, whose metadata must be kept from the state
+				PreConfig: func() {
+					server.DenyCreateTable = false
+				},
+				Config: config + `
+resource "cloudsql-auditlog_audit_log_rule" "after" {
+  username  = "user@%"
+  dbname    = "*"
+  object    = "*"
+  operation = "dcl"
+  op_result = "B"
+  ticket    = "SEC-1"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_log_rule.before", "owner", "security"),
+					testAccCheckAuditRuleMetadata(server, 1, false),
+					testAccCheckAuditRuleMetadata(server, 2, true),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAuditLogRuleResourceAlreadyExists(t *testing.T) {
+	server, providerConfig := newTestInstance(t)
+	server.AddRule(cloudsqlfake.Rule{
+		Username:  "user@%",
+		Dbname:    "*",
+		Object:    "*",
+		Operation: "*",
+		OpResult:  "B",
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_log_rule" "test" {
+  username  = "user@%"
+  dbname    = "*"
+  object    = "*"
+  operation = "*"
+  op_result = "B"
+}
+`,
+				ExpectError: regexp.MustCompile("Rule already exists"),
+			},
+		},
+	})
+}
+
+func testAccCheckActiveAuditRule(server *cloudsqlfake.Server, want cloudsqlfake.Rule) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		active := server.ActiveRules()
+		if len(active) != 1 {
+			return fmt.Errorf("expected 1 active rule, got %d", len(active))
+		}
+
+		if active[0] != want {
+			return fmt.Errorf("expected active rule %+v, got %+v", want, active[0])
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckAuditRuleMetadata(server *cloudsqlfake.Server, id int64, exists bool) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		if _, ok := server.Metadata(id); ok != exists {
+			return fmt.Errorf("expected metadata for rule %d to exist: %t", id, exists)
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckNoAuditRules(server *cloudsqlfake.Server) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		if rules := server.Rules(); len(rules) != 0 {
+			return fmt.Errorf("expected no audit rules, got %+v", rules)
+		}
+
+		return nil
+	}
+}
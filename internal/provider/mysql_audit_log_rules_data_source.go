@@ -6,7 +6,6 @@ package provider
 import (
 	"context"
 	"fmt"
-	"terraform-provider-cloudsql-auditlog/db"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
@@ -31,6 +30,7 @@ type auditLogRulesDataSource struct {
 
 // coffeesDataSourceModel maps the data source schema data.
 type auditLogRulesDataSourceModel struct {
+	Instance      types.String         `tfsdk:"instance"`
 	AuditLogRules []auditLogRulesModel `tfsdk:"audit_log_rules"`
 }
 
@@ -42,6 +42,12 @@ type auditLogRulesModel struct {
 	Object    types.String `tfsdk:"object"`
 	Operation types.String `tfsdk:"operation"`
 	OpResult  types.String `tfsdk:"op_result"`
+
+	ExpandedOperations types.List `tfsdk:"expanded_operations"`
+
+	Description types.String `tfsdk:"description"`
+	Owner       types.String `tfsdk:"owner"`
+	Ticket      types.String `tfsdk:"ticket"`
 }
 
 // Metadata returns the data source type name.
@@ -53,6 +59,9 @@ func (d *auditLogRulesDataSource) Metadata(_ context.Context, req datasource.Met
 func (d *auditLogRulesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
+			"instance": schema.StringAttribute{
+				Optional: true,
+			},
 			"audit_log_rules": schema.ListNestedAttribute{
 				Computed: true,
 				NestedObject: schema.NestedAttributeObject{
@@ -75,6 +84,19 @@ func (d *auditLogRulesDataSource) Schema(_ context.Context, _ datasource.SchemaR
 						"op_result": schema.StringAttribute{
 							Computed: true,
 						},
+						"expanded_operations": schema.ListAttribute{
+							Computed:    true,
+							ElementType: types.StringType,
+						},
+						"description": schema.StringAttribute{
+							Computed: true,
+						},
+						"owner": schema.StringAttribute{
+							Computed: true,
+						},
+						"ticket": schema.StringAttribute{
+							Computed: true,
+						},
 					},
 				},
 			},
@@ -85,9 +107,28 @@ func (d *auditLogRulesDataSource) Schema(_ context.Context, _ datasource.SchemaR
 // Read refreshes the Terraform state with the latest data.
 func (d *auditLogRulesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var state auditLogRulesDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if d.client.unknown {
+		deferDataSourceRead(req, resp)
+		return
+	}
 
-	q := db.New(d.client.client)
-	rules, err := q.GetAllAuditRules(ctx)
+	store, release, err := d.client.auditRuleStore(ctx, state.Instance)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+	defer release()
+
+	rules, err := store.List(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to query audit rules",
@@ -96,7 +137,14 @@ func (d *auditLogRulesDataSource) Read(ctx context.Context, req datasource.ReadR
 		return
 	}
 
+	ruleIndex := make(map[int64]int, len(rules))
 	for _, rule := range rules {
+		expandedOperations, diags := types.ListValueFrom(ctx, types.StringType, expandOperations(rule.Operation))
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
 		ruleState := auditLogRulesModel{
 			ID:        types.Int64Value(rule.ID),
 			Username:  types.StringValue(rule.Username),
@@ -104,12 +152,41 @@ func (d *auditLogRulesDataSource) Read(ctx context.Context, req datasource.ReadR
 			Object:    types.StringValue(rule.Object),
 			Operation: types.StringValue(rule.Operation),
 			OpResult:  types.StringValue(rule.OpResult),
+
+			ExpandedOperations: expandedOperations,
+
+			Description: types.StringNull(),
+			Owner:       types.StringNull(),
+			Ticket:      types.StringNull(),
 		}
 
+		ruleIndex[rule.ID] = len(state.AuditLogRules)
 		state.AuditLogRules = append(state.AuditLogRules, ruleState)
 	}
 
-	diags := resp.State.Set(ctx, &state)
+	if ms, ok := metadataStore(ctx, store); ok {
+		metadata, err := ms.ListMetadata(ctx)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to query audit rule metadata",
+				err.Error(),
+			)
+			return
+		}
+
+		for _, m := range metadata {
+			i, ok := ruleIndex[m.RuleID]
+			if !ok {
+				continue
+			}
+
+			state.AuditLogRules[i].Description = valueFromNullString(m.Description)
+			state.AuditLogRules[i].Owner = valueFromNullString(m.Owner)
+			state.AuditLogRules[i].Ticket = valueFromNullString(m.Ticket)
+		}
+	}
+
+	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -134,7 +211,7 @@ func (d *auditLogRulesDataSource) Configure(_ context.Context, req datasource.Co
 		return
 	}
 
-	if client.engine != "mysql" {
+	if !client.supportsEngine("mysql") {
 		resp.Diagnostics.AddError(
 			"Must use mysql engine for mysql types",
 			fmt.Sprintf("Configured engine is %q", client.engine),
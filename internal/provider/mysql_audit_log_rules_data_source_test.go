@@ -0,0 +1,46 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-cloudsql-auditlog/internal/cloudsqlfake"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccAuditLogRulesDataSource(t *testing.T) {
+	server, providerConfig := newTestInstance(t)
+	server.AddRule(cloudsqlfake.Rule{
+		Username:  "user@%",
+		Dbname:    "app",
+		Object:    "*",
+		Operation: "ddl,dcl",
+		OpResult:  "B",
+	})
+	server.AddRule(cloudsqlfake.Rule{
+		Username:  "*",
+		Dbname:    "*",
+		Object:    "*",
+		Operation: "*",
+		OpResult:  "E",
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `data "cloudsql-auditlog_audit_log_rules" "test" {}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_rules.test", "audit_log_rules.#", "2"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_rules.test", "audit_log_rules.0.id", "1"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_rules.test", "audit_log_rules.0.username", "user@%"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_rules.test", "audit_log_rules.0.expanded_operations.#", "7"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_rules.test", "audit_log_rules.1.op_result", "E"),
+				),
+			},
+		},
+	})
+}
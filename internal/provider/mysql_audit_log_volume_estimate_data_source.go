@@ -0,0 +1,358 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &auditLogVolumeEstimateDataSource{}
+	_ datasource.DataSourceWithConfigure = &auditLogVolumeEstimateDataSource{}
+)
+
+// NewAuditLogVolumeEstimateDataSource is a helper function to simplify the provider implementation.
+func NewAuditLogVolumeEstimateDataSource() datasource.DataSource {
+	return &auditLogVolumeEstimateDataSource{}
+}
+
+// auditLogVolumeEstimateDataSource is the data source implementation.
+type auditLogVolumeEstimateDataSource struct {
+	client CloudSqlClientAndConfig
+}
+
+// auditLogVolumeEstimateDataSourceModel maps the data source schema data.
+type auditLogVolumeEstimateDataSourceModel struct {
+	Instance       types.String `tfsdk:"instance"`
+	SampleDuration types.String `tfsdk:"sample_duration"`
+	Rules          types.List   `tfsdk:"rules"`
+
+	Estimates          []auditLogVolumeEstimateModel `tfsdk:"estimates"`
+	TotalEventsPerHour types.Float64                 `tfsdk:"total_events_per_hour"`
+	SampleSeconds      types.Float64                 `tfsdk:"sample_seconds"`
+	SysSchema          types.Bool                    `tfsdk:"sys_schema"`
+}
+
+// auditLogVolumeRuleModel maps the rules to estimate.
+type auditLogVolumeRuleModel struct {
+	Username  types.String `tfsdk:"username"`
+	DbName    types.String `tfsdk:"dbname"`
+	Object    types.String `tfsdk:"object"`
+	Operation types.String `tfsdk:"operation"`
+	OpResult  types.String `tfsdk:"op_result"`
+}
+
+// auditLogVolumeEstimateModel maps the estimated events of a rule.
+type auditLogVolumeEstimateModel struct {
+	ID            types.Int64   `tfsdk:"id"`
+	Username      types.String  `tfsdk:"username"`
+	DbName        types.String  `tfsdk:"dbname"`
+	Object        types.String  `tfsdk:"object"`
+	Operation     types.String  `tfsdk:"operation"`
+	OpResult      types.String  `tfsdk:"op_result"`
+	EventsPerHour types.Float64 `tfsdk:"events_per_hour"`
+}
+
+// Metadata returns the data source type name.
+func (d *auditLogVolumeEstimateDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_audit_log_volume_estimate"
+}
+
+// Schema defines the schema for the data source.
+func (d *auditLogVolumeEstimateDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"instance": schema.StringAttribute{
+				Optional: true,
+			},
+			"sample_duration": schema.StringAttribute{
+				Optional: true,
+			},
+			"rules": schema.ListNestedAttribute{
+				Optional: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"username": schema.StringAttribute{
+							Required: true,
+						},
+						"dbname": schema.StringAttribute{
+							Required: true,
+						},
+						"object": schema.StringAttribute{
+							Required: true,
+						},
+						"operation": schema.StringAttribute{
+							Required: true,
+						},
+						"op_result": schema.StringAttribute{
+							Required: true,
+						},
+					},
+				},
+			},
+			"estimates": schema.ListNestedAttribute{
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.Int64Attribute{
+							Computed: true,
+						},
+						"username": schema.StringAttribute{
+							Computed: true,
+						},
+						"dbname": schema.StringAttribute{
+							Computed: true,
+						},
+						"object": schema.StringAttribute{
+							Computed: true,
+						},
+						"operation": schema.StringAttribute{
+							Computed: true,
+						},
+						"op_result": schema.StringAttribute{
+							Computed: true,
+						},
+						"events_per_hour": schema.Float64Attribute{
+							Computed: true,
+						},
+					},
+				},
+			},
+			"total_events_per_hour": schema.Float64Attribute{
+				Computed: true,
+			},
+			"sample_seconds": schema.Float64Attribute{
+				Computed: true,
+			},
+			"sys_schema": schema.BoolAttribute{
+				Computed: true,
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *auditLogVolumeEstimateDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state auditLogVolumeEstimateDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var sampleDuration time.Duration
+	if !state.SampleDuration.IsNull() {
+		duration, err := time.ParseDuration(state.SampleDuration.ValueString())
+		if err == nil && duration <= 0 {
+			err = fmt.Errorf("sample duration must be positive, got %s", duration)
+		}
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("sample_duration"),
+				"Invalid sample duration",
+				err.Error(),
+			)
+			return
+		}
+		sampleDuration = duration
+	}
+
+	// without rules in the configuration the rules of the instance are
+	// estimated
+	var estimates []auditLogVolumeEstimateModel
+	if !state.Rules.IsNull() {
+		var rules []auditLogVolumeRuleModel
+		resp.Diagnostics.Append(state.Rules.ElementsAs(ctx, &rules, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		for i, rule := range rules {
+			switch rule.OpResult.ValueString() {
+			case "S", "U", "B", "E":
+			default:
+				resp.Diagnostics.AddAttributeError(
+					path.Root("rules").AtListIndex(i).AtName("op_result"),
+					"Invalid op_result",
+					fmt.Sprintf("Invalid op_result %q, allowed values: S, U, B, E", rule.OpResult.ValueString()),
+				)
+			}
+
+			estimates = append(estimates, auditLogVolumeEstimateModel{
+				ID:        types.Int64Null(),
+				Username:  rule.Username,
+				DbName:    rule.DbName,
+				Object:    rule.Object,
+				Operation: rule.Operation,
+				OpResult:  rule.OpResult,
+			})
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if d.client.unknown {
+		deferDataSourceRead(req, resp)
+		return
+	}
+
+	conn, err := d.client.connection(ctx, state.Instance)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+
+	if state.Rules.IsNull() {
+		store, release, err := d.client.auditRuleStore(ctx, state.Instance)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to connect to instance",
+				err.Error(),
+			)
+			return
+		}
+		defer release()
+
+		rules, err := store.List(ctx)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to query audit rules",
+				err.Error(),
+			)
+			return
+		}
+
+		for _, rule := range rules {
+			estimates = append(estimates, auditLogVolumeEstimateModel{
+				ID:        types.Int64Value(rule.ID),
+				Username:  types.StringValue(rule.Username),
+				DbName:    types.StringValue(rule.Dbname),
+				Object:    types.StringValue(rule.Object),
+				Operation: types.StringValue(rule.Operation),
+				OpResult:  types.StringValue(rule.OpResult),
+			})
+		}
+	}
+
+	statistics, err := readStatementStatistics(ctx, conn.db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to query statement statistics",
+			err.Error(),
+		)
+		return
+	}
+
+	// the summary counts every statement since the server started, a
+	// sample only the ones executed while waiting
+	if sampleDuration > 0 {
+		select {
+		case <-ctx.Done():
+			resp.Diagnostics.AddError(
+				"Unable to sample statement statistics",
+				ctx.Err().Error(),
+			)
+			return
+		case <-time.After(sampleDuration):
+		}
+
+		sample, err := readStatementStatistics(ctx, conn.db)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to query statement statistics",
+				err.Error(),
+			)
+			return
+		}
+		statistics = sample.since(statistics, sampleDuration.Seconds())
+	}
+
+	// the sys schema isn't installed everywhere, the databases of the rules
+	// are then ignored
+	shares, err := readSchemaShares(ctx, conn.db)
+	state.SysSchema = types.BoolValue(err == nil)
+	if err != nil {
+		tflog.Debug(ctx, "Unable to query the sys schema statement analysis", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	rules := make([]AuditRule, 0, len(estimates))
+	for _, estimate := range estimates {
+		rules = append(rules, AuditRule{
+			Username:  estimate.Username.ValueString(),
+			Dbname:    estimate.DbName.ValueString(),
+			Object:    estimate.Object.ValueString(),
+			Operation: estimate.Operation.ValueString(),
+			OpResult:  estimate.OpResult.ValueString(),
+		})
+	}
+
+	perRule, total := estimateAuditVolume(rules, statistics, shares)
+	for i := range estimates {
+		estimates[i].EventsPerHour = types.Float64Value(roundEstimate(perRule[i]))
+	}
+
+	state.Estimates = estimates
+	if state.Estimates == nil {
+		state.Estimates = []auditLogVolumeEstimateModel{}
+	}
+	state.TotalEventsPerHour = types.Float64Value(roundEstimate(total))
+	state.SampleSeconds = types.Float64Value(statistics.uptime)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// roundEstimate rounds the events per hour to two decimals.
+func roundEstimate(events float64) float64 {
+	return math.Round(events*100) / 100
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *auditLogVolumeEstimateDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(CloudSqlClientAndConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *sql.DB got %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !client.supportsEngine("mysql") {
+		resp.Diagnostics.AddError(
+			"Must use mysql engine for mysql types",
+			fmt.Sprintf("Configured engine is %q", client.engine),
+		)
+
+		return
+	}
+
+	d.client = client
+}
@@ -0,0 +1,166 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"terraform-provider-cloudsql-auditlog/internal/cloudsqlfake"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// testStatementSummary is an hour of statements on the test instances, the
+// hosts are the ones the clients connected from.
+var testStatementSummary = []cloudsqlfake.StatementSummary{
+	{User: "app", Host: "10.0.0.5", EventName: "statement/sql/select", Count: 7200, Errors: 720},
+	{User: "app", Host: "10.0.0.5", EventName: "statement/sql/insert", Count: 3600},
+	{User: "app", Host: "10.0.0.5", EventName: "statement/sql/create_table", Count: 36},
+	{User: "report", Host: "10.0.1.7", EventName: "statement/sql/select", Count: 1800},
+	{User: "app", Host: "10.0.0.5", EventName: "statement/com/Ping", Count: 500},
+}
+
+func TestEstimateAuditVolume(t *testing.T) {
+	statistics := statementStatistics{counts: make(map[statementKey]statementCount), uptime: 7200}
+	for _, summary := range testStatementSummary {
+		key := statementKey{account: mysqlAccount{User: summary.User, Host: summary.Host}, eventName: summary.EventName}
+		statistics.counts[key] = statementCount{statements: float64(summary.Count), errors: float64(summary.Errors)}
+	}
+
+	rules := []AuditRule{
+		{Username: "app@%", Dbname: "shop", Object: "*", Operation: "dql", OpResult: "B"},
+		{Username: "*", Dbname: "*", Object: "*", Operation: "*", OpResult: "B"},
+		{Username: "report@%", Dbname: "*", Object: "*", Operation: "*", OpResult: "E"},
+	}
+
+	perRule, total := estimateAuditVolume(rules, statistics, schemaShares{})
+
+	// without the sys schema the database of the first rule is ignored
+	want := []float64{3600, 6318, 900}
+	for i := range want {
+		if perRule[i] != want[i] {
+			t.Errorf("expected %v events per hour for rule %d, got %v", want[i], i, perRule[i])
+		}
+	}
+
+	if total != 5418 {
+		t.Errorf("expected 5418 events per hour in total, got %v", total)
+	}
+}
+
+func TestEstimateAuditVolumeClientHosts(t *testing.T) {
+	statistics := statementStatistics{counts: make(map[statementKey]statementCount), uptime: 3600}
+	statistics.counts[statementKey{account: mysqlAccount{User: "app", Host: "10.0.0.5"}, eventName: "statement/sql/insert"}] = statementCount{statements: 100}
+	statistics.counts[statementKey{account: mysqlAccount{User: "app", Host: "Batch.internal"}, eventName: "statement/sql/insert"}] = statementCount{statements: 10}
+
+	rules := []AuditRule{
+		{Username: "app@%", Dbname: "*", Object: "*", Operation: "dml", OpResult: "B"},
+		{Username: "app@10.0.0._", Dbname: "*", Object: "*", Operation: "dml", OpResult: "B"},
+		{Username: "app@10.0.1.%", Dbname: "*", Object: "*", Operation: "dml", OpResult: "B"},
+		{Username: "app@batch.internal", Dbname: "*", Object: "*", Operation: "dml", OpResult: "B"},
+		{Username: "app@*.internal", Dbname: "*", Object: "*", Operation: "dml", OpResult: "B"},
+	}
+
+	perRule, _ := estimateAuditVolume(rules, statistics, schemaShares{})
+
+	want := []float64{110, 100, 0, 10, 10}
+	for i := range want {
+		if perRule[i] != want[i] {
+			t.Errorf("expected %v events per hour for %s, got %v", want[i], rules[i].Username, perRule[i])
+		}
+	}
+}
+
+func TestStatementOperation(t *testing.T) {
+	for eventName, want := range map[string]string{
+		"statement/sql/select":        "select",
+		"statement/sql/insert_select": "insert",
+		"statement/sql/create_table":  "create",
+		"statement/sql/show_grants":   "show",
+		"statement/sql/grant_roles":   "grant",
+		"statement/sql/set_option":    "set_option",
+		"statement/com/Ping":          "",
+	} {
+		if got, _ := statementOperation(eventName); got != want {
+			t.Errorf("expected operation %q for %s, got %q", want, eventName, got)
+		}
+	}
+}
+
+func TestAccAuditLogVolumeEstimateDataSource(t *testing.T) {
+	server, providerConfig := newTestInstance(t)
+	server.StatementSummary = testStatementSummary
+	server.StatementAnalysis = []cloudsqlfake.StatementAnalysis{
+		{Db: "shop", Query: "SELECT * FROM `orders`", ExecCount: 3},
+		{Db: "reports", Query: "SELECT COUNT ( * ) FROM `orders`", ExecCount: 1},
+		{Db: "shop", Query: "INSERT INTO `orders` VALUES (...)", ExecCount: 2},
+	}
+	server.AddRule(cloudsqlfake.Rule{
+		Username:  "*",
+		Dbname:    "*",
+		Object:    "*",
+		Operation: "*",
+		OpResult:  "B",
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "cloudsql-auditlog_audit_log_volume_estimate" "test" {
+  rules = [
+    { username = "*", dbname = "*", object = "*", operation = "*", op_result = "U" },
+    { username = "app@%", dbname = "shop", object = "*", operation = "dql", op_result = "B" },
+    { username = "report@%", dbname = "*", object = "*", operation = "*", op_result = "E" },
+    { username = "*", dbname = "*", object = "*", operation = "ddl", op_result = "B" },
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_volume_estimate.test", "sys_schema", "true"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_volume_estimate.test", "sample_seconds", "3600"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_volume_estimate.test", "estimates.#", "4"),
+					resource.TestCheckNoResourceAttr("data.cloudsql-auditlog_audit_log_volume_estimate.test", "estimates.0.id"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_volume_estimate.test", "estimates.0.events_per_hour", "720"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_volume_estimate.test", "estimates.1.events_per_hour", "5400"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_volume_estimate.test", "estimates.2.events_per_hour", "1800"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_volume_estimate.test", "estimates.3.events_per_hour", "36"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_volume_estimate.test", "total_events_per_hour", "5616"),
+				),
+			},
+			{
+				Config: providerConfig + `data "cloudsql-auditlog_audit_log_volume_estimate" "test" {}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_volume_estimate.test", "estimates.#", "1"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_volume_estimate.test", "estimates.0.id", "1"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_volume_estimate.test", "estimates.0.events_per_hour", "12636"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_volume_estimate.test", "total_events_per_hour", "12636"),
+				),
+			},
+			{
+				Config: providerConfig + `
+data "cloudsql-auditlog_audit_log_volume_estimate" "test" {
+  sample_duration = "100ms"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_volume_estimate.test", "sample_seconds", "0.1"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_volume_estimate.test", "total_events_per_hour", "0"),
+				),
+			},
+			{
+				Config: providerConfig + `
+data "cloudsql-auditlog_audit_log_volume_estimate" "test" {
+  rules = [
+    { username = "*", dbname = "*", object = "*", operation = "*", op_result = "X" },
+  ]
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid op_result "X"`),
+			},
+		},
+	})
+}
@@ -0,0 +1,189 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"terraform-provider-cloudsql-auditlog/db"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ AuditRuleStore         = &mysqlAuditRuleStore{}
+	_ AuditRuleMetadataStore = &mysqlAuditRuleStore{}
+)
+
+// mysqlAuditRuleStore manages the rules with the mysql.cloudsql_*_audit_rule
+// procedures through the sqlc queries. The queries share a single connection
+// so that the out variables of the procedures can be read after each call.
+type mysqlAuditRuleStore struct {
+	conn   *instanceConnection
+	traced *tracingDBTX
+	q      *db.Queries
+}
+
+func newMySQLAuditRuleStore(ctx context.Context, conn *instanceConnection) (AuditRuleStore, func(), error) {
+	traced, release, err := conn.tracedConn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &mysqlAuditRuleStore{conn: conn, traced: traced, q: db.New(traced)}, release, nil
+}
+
+// procedureResult reads the @outval and @outmsg of the last procedure call,
+// the procedures report their failures there instead of raising an error.
+func (s *mysqlAuditRuleStore) procedureResult(ctx context.Context) error {
+	outval, outmsg, err := s.traced.procedureOutVars(ctx)
+	if err != nil {
+		return err
+	}
+
+	if outval.Int64 == 0 {
+		return nil
+	}
+
+	if strings.Contains(outmsg.String, "does not exist") {
+		return fmt.Errorf("%w: %s", ErrAuditRuleNotFound, outmsg.String)
+	}
+
+	return fmt.Errorf("procedure failed with %d: %s", outval.Int64, outmsg.String)
+}
+
+func (s *mysqlAuditRuleStore) List(ctx context.Context) ([]AuditRule, error) {
+	rules, err := s.q.GetAllAuditRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]AuditRule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, AuditRule(rule))
+	}
+
+	return result, nil
+}
+
+func (s *mysqlAuditRuleStore) Get(ctx context.Context, id int64) (AuditRule, error) {
+	rule, err := s.q.ReadAuditLogRuleByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return AuditRule{}, ErrAuditRuleNotFound
+	} else if err != nil {
+		return AuditRule{}, err
+	}
+
+	return AuditRule(rule), nil
+}
+
+func (s *mysqlAuditRuleStore) Find(ctx context.Context, rule AuditRule) (int64, error) {
+	id, err := s.q.ReadAuditRuleIDAfterCreate(ctx, db.ReadAuditRuleIDAfterCreateParams{
+		Username:  rule.Username,
+		Dbname:    rule.Dbname,
+		Object:    rule.Object,
+		Operation: rule.Operation,
+		OpResult:  rule.OpResult,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrAuditRuleNotFound
+	}
+
+	return id, err
+}
+
+func (s *mysqlAuditRuleStore) Create(ctx context.Context, rule AuditRule) (int64, error) {
+	err := s.q.CreateAuditRule(ctx, db.CreateAuditRuleParams{
+		Username:  rule.Username,
+		Dbname:    rule.Dbname,
+		Object:    rule.Object,
+		Operation: rule.Operation,
+		OpResult:  rule.OpResult,
+	})
+	if err == nil {
+		err = s.procedureResult(ctx)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// the procedure doesn't return the id of the new rule
+	return s.Find(ctx, rule)
+}
+
+func (s *mysqlAuditRuleStore) Update(ctx context.Context, rule AuditRule) error {
+	err := s.q.UpdatedAuditRuleByID(ctx, db.UpdatedAuditRuleByIDParams{
+		ID:        rule.ID,
+		Username:  rule.Username,
+		Dbname:    rule.Dbname,
+		Object:    rule.Object,
+		Operation: rule.Operation,
+		OpResult:  rule.OpResult,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.procedureResult(ctx)
+}
+
+func (s *mysqlAuditRuleStore) Delete(ctx context.Context, id int64) error {
+	if err := s.q.DeleteAuditRuleByID(ctx, id); err != nil {
+		return err
+	}
+
+	return s.procedureResult(ctx)
+}
+
+func (s *mysqlAuditRuleStore) Reload(ctx context.Context) error {
+	if err := s.q.ReloadAuditRules(ctx); err != nil {
+		return err
+	}
+
+	return s.procedureResult(ctx)
+}
+
+func (s *mysqlAuditRuleStore) MetadataAvailable(ctx context.Context) bool {
+	return s.conn.metadata.Available(ctx, s.traced, false)
+}
+
+func (s *mysqlAuditRuleStore) PrepareMetadata(ctx context.Context) bool {
+	return s.conn.metadata.Available(ctx, s.traced, true)
+}
+
+func (s *mysqlAuditRuleStore) ListMetadata(ctx context.Context) ([]AuditRuleMetadata, error) {
+	metadata, err := s.q.GetAllAuditRuleMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]AuditRuleMetadata, 0, len(metadata))
+	for _, m := range metadata {
+		result = append(result, AuditRuleMetadata(m))
+	}
+
+	return result, nil
+}
+
+func (s *mysqlAuditRuleStore) GetMetadata(ctx context.Context, ruleID int64) (AuditRuleMetadata, error) {
+	metadata, err := s.q.ReadAuditRuleMetadataByRuleID(ctx, ruleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return AuditRuleMetadata{}, ErrAuditRuleMetadataNotFound
+	} else if err != nil {
+		return AuditRuleMetadata{}, err
+	}
+
+	return AuditRuleMetadata(metadata), nil
+}
+
+func (s *mysqlAuditRuleStore) SetMetadata(ctx context.Context, metadata AuditRuleMetadata) error {
+	return s.q.UpsertAuditRuleMetadata(ctx, db.UpsertAuditRuleMetadataParams(metadata))
+}
+
+func (s *mysqlAuditRuleStore) DeleteMetadata(ctx context.Context, ruleID int64) error {
+	return s.q.DeleteAuditRuleMetadataByRuleID(ctx, ruleID)
+}
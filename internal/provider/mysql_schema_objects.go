@@ -0,0 +1,109 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"database/sql"
+)
+
+const getAllSchemas = "SELECT SCHEMA_NAME FROM information_schema.SCHEMATA"
+
+const getAllSchemaObjects = `SELECT TABLE_SCHEMA, TABLE_NAME FROM information_schema.TABLES
+UNION ALL
+SELECT ROUTINE_SCHEMA, ROUTINE_NAME FROM information_schema.ROUTINES`
+
+// schemaCatalog holds the databases and the tables, views and routines in
+// each of them.
+type schemaCatalog struct {
+	schemas map[string]map[string]bool
+}
+
+func readSchemaCatalog(ctx context.Context, conn *sql.DB) (schemaCatalog, error) {
+	catalog := schemaCatalog{schemas: make(map[string]map[string]bool)}
+
+	rows, err := conn.QueryContext(ctx, getAllSchemas)
+	if err != nil {
+		return catalog, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return catalog, err
+		}
+		catalog.schemas[name] = make(map[string]bool)
+	}
+	if err := rows.Err(); err != nil {
+		return catalog, err
+	}
+
+	objects, err := conn.QueryContext(ctx, getAllSchemaObjects)
+	if err != nil {
+		return catalog, err
+	}
+	defer objects.Close()
+
+	for objects.Next() {
+		var schema, name string
+		if err := objects.Scan(&schema, &name); err != nil {
+			return catalog, err
+		}
+
+		if _, ok := catalog.schemas[schema]; !ok {
+			catalog.schemas[schema] = make(map[string]bool)
+		}
+		catalog.schemas[schema][name] = true
+	}
+
+	return catalog, objects.Err()
+}
+
+// missing returns the concrete (non-wildcard) databases and objects of an
+// audit rule that don't exist. Objects are looked up in every database that
+// the dbname pattern covers.
+func (c schemaCatalog) missing(dbname, object string) []string {
+	var missing []string
+	var databases []string
+
+	for _, entry := range splitRuleList(dbname) {
+		name := unquoteIdentifier(entry)
+		if isWildcard(name) || hasWildcard(name) {
+			for schema := range c.schemas {
+				if isWildcard(name) || wildcardMatch(name, schema) {
+					databases = append(databases, schema)
+				}
+			}
+			continue
+		}
+
+		if _, ok := c.schemas[name]; !ok {
+			missing = append(missing, name)
+			continue
+		}
+		databases = append(databases, name)
+	}
+
+	for _, entry := range splitRuleList(object) {
+		name := unquoteIdentifier(entry)
+		if isWildcard(name) || hasWildcard(name) {
+			continue
+		}
+
+		found := false
+		for _, schema := range databases {
+			if c.schemas[schema][name] {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			missing = append(missing, name)
+		}
+	}
+
+	return missing
+}
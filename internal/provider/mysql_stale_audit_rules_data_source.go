@@ -0,0 +1,201 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &staleAuditRulesDataSource{}
+	_ datasource.DataSourceWithConfigure = &staleAuditRulesDataSource{}
+)
+
+// NewStaleAuditRulesDataSource is a helper function to simplify the provider implementation.
+func NewStaleAuditRulesDataSource() datasource.DataSource {
+	return &staleAuditRulesDataSource{}
+}
+
+// staleAuditRulesDataSource is the data source implementation.
+type staleAuditRulesDataSource struct {
+	client CloudSqlClientAndConfig
+}
+
+// staleAuditRulesDataSourceModel maps the data source schema data.
+type staleAuditRulesDataSourceModel struct {
+	Instance      types.String          `tfsdk:"instance"`
+	AuditLogRules []staleAuditRuleModel `tfsdk:"audit_log_rules"`
+}
+
+// staleAuditRuleModel maps the rules referencing missing databases or objects.
+type staleAuditRuleModel struct {
+	ID        types.Int64    `tfsdk:"id"`
+	Username  types.String   `tfsdk:"username"`
+	DbName    types.String   `tfsdk:"dbname"`
+	Object    types.String   `tfsdk:"object"`
+	Operation types.String   `tfsdk:"operation"`
+	OpResult  types.String   `tfsdk:"op_result"`
+	Missing   []types.String `tfsdk:"missing"`
+}
+
+// Metadata returns the data source type name.
+func (d *staleAuditRulesDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_stale_audit_rules"
+}
+
+// Schema defines the schema for the data source.
+func (d *staleAuditRulesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"instance": schema.StringAttribute{
+				Optional: true,
+			},
+			"audit_log_rules": schema.ListNestedAttribute{
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.Int64Attribute{
+							Computed: true,
+						},
+						"username": schema.StringAttribute{
+							Computed: true,
+						},
+						"dbname": schema.StringAttribute{
+							Computed: true,
+						},
+						"object": schema.StringAttribute{
+							Computed: true,
+						},
+						"operation": schema.StringAttribute{
+							Computed: true,
+						},
+						"op_result": schema.StringAttribute{
+							Computed: true,
+						},
+						"missing": schema.ListAttribute{
+							Computed:    true,
+							ElementType: types.StringType,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *staleAuditRulesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state staleAuditRulesDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if d.client.unknown {
+		deferDataSourceRead(req, resp)
+		return
+	}
+
+	conn, err := d.client.connection(ctx, state.Instance)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+
+	store, release, err := d.client.auditRuleStore(ctx, state.Instance)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+	defer release()
+
+	rules, err := store.List(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to query audit rules",
+			err.Error(),
+		)
+		return
+	}
+
+	catalog, err := readSchemaCatalog(ctx, conn.db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to query databases and objects",
+			err.Error(),
+		)
+		return
+	}
+
+	state.AuditLogRules = []staleAuditRuleModel{}
+	for _, rule := range rules {
+		missing := catalog.missing(rule.Dbname, rule.Object)
+		if len(missing) == 0 {
+			continue
+		}
+
+		ruleState := staleAuditRuleModel{
+			ID:        types.Int64Value(rule.ID),
+			Username:  types.StringValue(rule.Username),
+			DbName:    types.StringValue(rule.Dbname),
+			Object:    types.StringValue(rule.Object),
+			Operation: types.StringValue(rule.Operation),
+			OpResult:  types.StringValue(rule.OpResult),
+		}
+		for _, m := range missing {
+			ruleState.Missing = append(ruleState.Missing, types.StringValue(m))
+		}
+
+		state.AuditLogRules = append(state.AuditLogRules, ruleState)
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *staleAuditRulesDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(CloudSqlClientAndConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *sql.DB got %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !client.supportsEngine("mysql") {
+		resp.Diagnostics.AddError(
+			"Must use mysql engine for mysql types",
+			fmt.Sprintf("Configured engine is %q", client.engine),
+		)
+
+		return
+	}
+
+	d.client = client
+}
@@ -0,0 +1,56 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-cloudsql-auditlog/internal/cloudsqlfake"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccStaleAuditRulesDataSource(t *testing.T) {
+	server, providerConfig := newTestInstance(t)
+	server.Schemas["app"] = []string{"orders"}
+	server.AddRule(cloudsqlfake.Rule{
+		Username:  "*",
+		Dbname:    "app",
+		Object:    "orders",
+		Operation: "dml",
+		OpResult:  "B",
+	})
+	server.AddRule(cloudsqlfake.Rule{
+		Username:  "*",
+		Dbname:    "app",
+		Object:    "orders,invoices",
+		Operation: "dml",
+		OpResult:  "B",
+	})
+	server.AddRule(cloudsqlfake.Rule{
+		Username:  "*",
+		Dbname:    "legacy",
+		Object:    "*",
+		Operation: "ddl",
+		OpResult:  "B",
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `data "cloudsql-auditlog_stale_audit_rules" "test" {}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_stale_audit_rules.test", "audit_log_rules.#", "2"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_stale_audit_rules.test", "audit_log_rules.0.id", "2"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_stale_audit_rules.test", "audit_log_rules.0.missing.#", "1"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_stale_audit_rules.test", "audit_log_rules.0.missing.0", "invoices"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_stale_audit_rules.test", "audit_log_rules.1.id", "3"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_stale_audit_rules.test", "audit_log_rules.1.dbname", "legacy"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_stale_audit_rules.test", "audit_log_rules.1.missing.0", "legacy"),
+				),
+			},
+		},
+	})
+}
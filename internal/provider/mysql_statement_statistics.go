@@ -0,0 +1,248 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+const getStatementSummary = `SELECT USER, HOST, EVENT_NAME, COUNT_STAR, SUM_ERRORS
+FROM performance_schema.events_statements_summary_by_account_by_event_name
+WHERE USER IS NOT NULL AND COUNT_STAR > 0`
+
+const getUptime = "SELECT VARIABLE_VALUE FROM performance_schema.global_status WHERE VARIABLE_NAME = 'Uptime'"
+
+const getStatementAnalysis = "SELECT db, query, exec_count FROM sys.`x$statement_analysis`"
+
+// statementEventOperations maps the statement/sql event names that don't
+// start with the audit rule operation they're logged as.
+var statementEventOperations = map[string]string{
+	"insert_select":  "insert",
+	"replace_select": "replace",
+	"update_multi":   "update",
+	"delete_multi":   "delete",
+	"call_procedure": "call",
+	"grant_roles":    "grant",
+	"revoke_all":     "revoke",
+	"revoke_roles":   "revoke",
+}
+
+// statementKey identifies a row of the statement summary by account.
+type statementKey struct {
+	account   mysqlAccount
+	eventName string
+}
+
+// statementCount is the number of statements and the failed ones among them.
+type statementCount struct {
+	statements float64
+	errors     float64
+}
+
+// statementStatistics are the statement counts of the accounts since the
+// server started (or the summary was truncated) and the server uptime in
+// seconds.
+type statementStatistics struct {
+	counts map[statementKey]statementCount
+	uptime float64
+}
+
+// schemaShares holds the executions per operation and database from the sys
+// schema statement analysis, used to estimate how many statements of an
+// account touch the databases of a rule.
+type schemaShares struct {
+	executions map[string]map[string]float64
+}
+
+func readStatementStatistics(ctx context.Context, conn *sql.DB) (statementStatistics, error) {
+	statistics := statementStatistics{counts: make(map[statementKey]statementCount)}
+
+	if err := conn.QueryRowContext(ctx, getUptime).Scan(&statistics.uptime); err != nil {
+		return statistics, err
+	}
+
+	rows, err := conn.QueryContext(ctx, getStatementSummary)
+	if err != nil {
+		return statistics, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key statementKey
+		var count statementCount
+		if err := rows.Scan(&key.account.User, &key.account.Host, &key.eventName, &count.statements, &count.errors); err != nil {
+			return statistics, err
+		}
+		statistics.counts[key] = count
+	}
+
+	return statistics, rows.Err()
+}
+
+// since returns the statements executed after the earlier statistics, the
+// seconds in between are measured by the caller as the uptime only has a
+// resolution of a second.
+func (s statementStatistics) since(earlier statementStatistics, seconds float64) statementStatistics {
+	delta := statementStatistics{
+		counts: make(map[statementKey]statementCount),
+		uptime: seconds,
+	}
+
+	for key, count := range s.counts {
+		before := earlier.counts[key]
+		delta.counts[key] = statementCount{
+			statements: count.statements - before.statements,
+			errors:     count.errors - before.errors,
+		}
+	}
+
+	return delta
+}
+
+func readSchemaShares(ctx context.Context, conn *sql.DB) (schemaShares, error) {
+	shares := schemaShares{executions: make(map[string]map[string]float64)}
+
+	rows, err := conn.QueryContext(ctx, getStatementAnalysis)
+	if err != nil {
+		return shares, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var db sql.NullString
+		var query string
+		var executions float64
+		if err := rows.Scan(&db, &query, &executions); err != nil {
+			return shares, err
+		}
+
+		fields := strings.Fields(query)
+		if len(fields) == 0 {
+			continue
+		}
+
+		operation := strings.ToLower(fields[0])
+		if shares.executions[operation] == nil {
+			shares.executions[operation] = make(map[string]float64)
+		}
+		shares.executions[operation][db.String] += executions
+	}
+
+	return shares, rows.Err()
+}
+
+// share returns the fraction of the executions of the operation in the
+// databases of the dbname pattern. Operations without any executions use
+// the fraction over every operation, and without statement analysis every
+// statement is assumed to be in the databases.
+func (s schemaShares) share(dbname, operation string) float64 {
+	executions := s.executions[operation]
+	if len(executions) == 0 {
+		executions = make(map[string]float64)
+		for _, databases := range s.executions {
+			for db, n := range databases {
+				executions[db] += n
+			}
+		}
+	}
+
+	var matched, total float64
+	for db, n := range executions {
+		total += n
+		if ruleFieldMatches(dbname, db) {
+			matched += n
+		}
+	}
+
+	if total == 0 {
+		return 1
+	}
+
+	return matched / total
+}
+
+// statementOperation returns the audit rule operation of a statement event,
+// only statement/sql events are logged by the audit plugin.
+func statementOperation(eventName string) (string, bool) {
+	name, ok := strings.CutPrefix(eventName, "statement/sql/")
+	if !ok {
+		return "", false
+	}
+
+	if operation, ok := statementEventOperations[name]; ok {
+		return operation, true
+	}
+
+	// create_table, drop_user, show_grants, rename_table, ...
+	operation, _, _ := strings.Cut(name, "_")
+	switch operation {
+	case "create", "alter", "drop", "show", "rename":
+		return operation, true
+	}
+
+	return name, true
+}
+
+// estimateAuditVolume returns the events per hour that each rule matches in
+// the statistics and the events written to the audit log by all of them,
+// where exclusion rules remove the events they match. The object of the
+// rules isn't known from the statistics, so rules limited to some objects
+// are estimated as if they covered the whole database.
+func estimateAuditVolume(rules []AuditRule, statistics statementStatistics, shares schemaShares) ([]float64, float64) {
+	perRule := make([]float64, len(rules))
+	var total float64
+
+	if statistics.uptime <= 0 {
+		return perRule, total
+	}
+	perHour := 3600 / statistics.uptime
+
+	for key, count := range statistics.counts {
+		operation, ok := statementOperation(key.eventName)
+		if !ok {
+			continue
+		}
+
+		for result, events := range map[string]float64{
+			"S": count.statements - count.errors,
+			"U": count.errors,
+		} {
+			if events <= 0 {
+				continue
+			}
+			events *= perHour
+
+			event := auditEvent{
+				User:      key.account.User,
+				Host:      key.account.Host,
+				Operation: operation,
+				Result:    result,
+			}
+
+			var included, excluded float64
+			for i, rule := range rules {
+				matcher := rule
+				matcher.Dbname, matcher.Object = "*", "*"
+				if !ruleMatchesEvent(matcher, event) {
+					continue
+				}
+
+				share := shares.share(rule.Dbname, operation)
+				perRule[i] += events * share
+
+				if strings.EqualFold(rule.OpResult, "E") {
+					excluded = max(excluded, share)
+				} else {
+					included = max(included, share)
+				}
+			}
+
+			total += events * included * (1 - excluded)
+		}
+	}
+
+	return perRule, total
+}
@@ -0,0 +1,155 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &mysqlUsersDataSource{}
+	_ datasource.DataSourceWithConfigure = &mysqlUsersDataSource{}
+)
+
+// NewMySQLUsersDataSource is a helper function to simplify the provider implementation.
+func NewMySQLUsersDataSource() datasource.DataSource {
+	return &mysqlUsersDataSource{}
+}
+
+// mysqlUsersDataSource is the data source implementation.
+type mysqlUsersDataSource struct {
+	client CloudSqlClientAndConfig
+}
+
+// mysqlUsersDataSourceModel maps the data source schema data.
+type mysqlUsersDataSourceModel struct {
+	Instance types.String     `tfsdk:"instance"`
+	Username types.String     `tfsdk:"username"`
+	Users    []mysqlUserModel `tfsdk:"users"`
+}
+
+// mysqlUserModel maps mysql.user rows.
+type mysqlUserModel struct {
+	User types.String `tfsdk:"user"`
+	Host types.String `tfsdk:"host"`
+}
+
+// Metadata returns the data source type name.
+func (d *mysqlUsersDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_mysql_users"
+}
+
+// Schema defines the schema for the data source.
+func (d *mysqlUsersDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"instance": schema.StringAttribute{
+				Optional: true,
+			},
+			"username": schema.StringAttribute{
+				Optional: true,
+			},
+			"users": schema.ListNestedAttribute{
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"user": schema.StringAttribute{
+							Computed: true,
+						},
+						"host": schema.StringAttribute{
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *mysqlUsersDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state mysqlUsersDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if d.client.unknown {
+		deferDataSourceRead(req, resp)
+		return
+	}
+
+	conn, err := d.client.connection(ctx, state.Instance)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+
+	accounts, err := readMySQLAccounts(ctx, conn.db)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to query mysql users",
+			err.Error(),
+		)
+		return
+	}
+
+	if !state.Username.IsNull() {
+		accounts = matchingAccounts(state.Username.ValueString(), accounts)
+	}
+
+	state.Users = []mysqlUserModel{}
+	for _, a := range accounts {
+		state.Users = append(state.Users, mysqlUserModel{
+			User: types.StringValue(a.User),
+			Host: types.StringValue(a.Host),
+		})
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *mysqlUsersDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(CloudSqlClientAndConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *sql.DB got %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !client.supportsEngine("mysql") {
+		resp.Diagnostics.AddError(
+			"Must use mysql engine for mysql types",
+			fmt.Sprintf("Configured engine is %q", client.engine),
+		)
+
+		return
+	}
+
+	d.client = client
+}
@@ -0,0 +1,57 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-cloudsql-auditlog/internal/cloudsqlfake"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccMySQLUsersDataSource(t *testing.T) {
+	server, providerConfig := newTestInstance(t)
+	server.Accounts = append(server.Accounts,
+		cloudsqlfake.Account{User: "app", Host: "10.%"},
+		cloudsqlfake.Account{User: "app", Host: "localhost"},
+		cloudsqlfake.Account{User: "report", Host: "%"},
+	)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `data "cloudsql-auditlog_mysql_users" "test" {}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_mysql_users.test", "users.#", "4"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.cloudsql-auditlog_mysql_users.test", "users.*", map[string]string{
+						"user": "app",
+						"host": "10.%",
+					}),
+				),
+			},
+			{
+				Config: providerConfig + `
+data "cloudsql-auditlog_mysql_users" "test" {
+  username = "app@10.*"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_mysql_users.test", "users.#", "1"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_mysql_users.test", "users.0.user", "app"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_mysql_users.test", "users.0.host", "10.%"),
+				),
+			},
+			{
+				Config: providerConfig + `
+data "cloudsql-auditlog_mysql_users" "test" {
+  username = "nobody"
+}
+`,
+				Check: resource.TestCheckResourceAttr("data.cloudsql-auditlog_mysql_users.test", "users.#", "0"),
+			},
+		},
+	})
+}
@@ -0,0 +1,58 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &normalizeAccountFunction{}
+
+func NewNormalizeAccountFunction() function.Function {
+	return &normalizeAccountFunction{}
+}
+
+type normalizeAccountFunction struct{}
+
+func (f *normalizeAccountFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "normalize_account"
+}
+
+func (f *normalizeAccountFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Build an audit rule username from a user and host",
+		Description: "Returns the user@host string in the format cloudsql stores in audit_log_rules, quoting the user and host with backticks when needed. An empty host matches any host.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "user",
+				Description: "MySQL user name, e.g., the name of a google_sql_user.",
+			},
+			function.StringParameter{
+				Name:        "host",
+				Description: "MySQL host pattern, e.g., the host of a google_sql_user.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *normalizeAccountFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var user, host string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &user, &host))
+	if resp.Error != nil {
+		return
+	}
+
+	account, err := formatRuleAccount(user, host)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, account))
+}
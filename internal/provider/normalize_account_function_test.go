@@ -0,0 +1,83 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// testRunFunction runs the provider function with the arguments, result is
+// an unknown value of the return type.
+func testRunFunction(t *testing.T, f function.Function, result attr.Value, args ...attr.Value) (attr.Value, *function.FuncError) {
+	t.Helper()
+
+	resp := function.RunResponse{Result: function.NewResultData(result)}
+	f.Run(context.Background(), function.RunRequest{Arguments: function.NewArgumentsData(args)}, &resp)
+
+	return resp.Result.Value(), resp.Error
+}
+
+func TestNormalizeAccountFunction(t *testing.T) {
+	tests := []struct {
+		user string
+		host string
+		want string
+	}{
+		{"app", "%", "app@%"},
+		{"app", "10.%", "app@10.%"},
+		{"app", "", "app@*"},
+		{"*", "", "*@*"},
+		{"*", "%", "*@%"},
+		{"app.user", "localhost", "`app.user`@localhost"},
+		{"app`s", "%", "`app``s`@%"},
+		{"app", "host name", "app@`host name`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.user+"@"+tt.host, func(t *testing.T) {
+			got, err := testRunFunction(t, NewNormalizeAccountFunction(), types.StringUnknown(), types.StringValue(tt.user), types.StringValue(tt.host))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.(types.String).ValueString() != tt.want {
+				t.Errorf("expected %q, got %s", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := testRunFunction(t, NewNormalizeAccountFunction(), types.StringUnknown(), types.StringValue(""), types.StringValue("%")); err == nil {
+		t.Error("expected an empty user to fail")
+	}
+}
+
+// TestNormalizeParseAccountRoundTrip checks that both functions agree on the
+// default host.
+func TestNormalizeParseAccountRoundTrip(t *testing.T) {
+	for _, account := range [][2]string{{"app", ""}, {"app", "%"}, {"*", ""}, {"app.user", "10.%"}} {
+		normalized, err := testRunFunction(t, NewNormalizeAccountFunction(), types.StringUnknown(), types.StringValue(account[0]), types.StringValue(account[1]))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parsed, err := testRunFunction(t, NewParseAccountFunction(), types.ObjectUnknown(accountAttributeTypes), normalized)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		attributes := parsed.(types.Object).Attributes()
+		host := account[1]
+		if host == "" {
+			host = "*"
+		}
+		if attributes["user"].(types.String).ValueString() != account[0] || attributes["host"].(types.String).ValueString() != host {
+			t.Errorf("expected %s@%s after parsing %s, got %v", account[0], host, normalized, attributes)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// operationClassesVersion must be bumped whenever operationClasses changes,
+// it is part of the expand_operations function documentation so that users
+// can tell which expansion their policies were written against.
+const operationClassesVersion = 1
+
+// operationClasses maps the operation classes accepted by cloudsql in the
+// audit rule operation field to the statements they cover.
+var operationClasses = map[string][]string{
+	"dql":  {"select"},
+	"dml":  {"insert", "update", "delete", "replace", "load"},
+	"ddl":  {"create", "alter", "drop", "rename", "truncate"},
+	"dcl":  {"grant", "revoke"},
+	"show": {"show"},
+	"call": {"call"},
+}
+
+// expandOperations expands the operation classes in a comma separated audit
+// rule operation into the concrete statements, keeping the order in which
+// they first appear and dropping duplicates. Wildcards are kept as is.
+func expandOperations(operation string) []string {
+	seen := make(map[string]bool)
+	expanded := []string{}
+
+	add := func(op string) {
+		if !seen[op] {
+			seen[op] = true
+			expanded = append(expanded, op)
+		}
+	}
+
+	for _, entry := range splitRuleList(operation) {
+		entry = strings.ToLower(entry)
+		if entry == "" {
+			continue
+		}
+
+		statements, ok := operationClasses[entry]
+		if !ok {
+			add(entry)
+			continue
+		}
+
+		for _, statement := range statements {
+			add(statement)
+		}
+	}
+
+	return expanded
+}
+
+func expandedOperationsValue(ctx context.Context, operation types.String) (types.List, diag.Diagnostics) {
+	if operation.IsUnknown() {
+		return types.ListUnknown(types.StringType), nil
+	}
+
+	if operation.IsNull() {
+		return types.ListNull(types.StringType), nil
+	}
+
+	return types.ListValueFrom(ctx, types.StringType, expandOperations(operation.ValueString()))
+}
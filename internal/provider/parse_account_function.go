@@ -0,0 +1,72 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &parseAccountFunction{}
+
+var accountAttributeTypes = map[string]attr.Type{
+	"user": types.StringType,
+	"host": types.StringType,
+}
+
+func NewParseAccountFunction() function.Function {
+	return &parseAccountFunction{}
+}
+
+type parseAccountFunction struct{}
+
+func (f *parseAccountFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_account"
+}
+
+func (f *parseAccountFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Split an audit rule username into user and host",
+		Description: "Parses a user@host audit rule username, removing any quoting, and returns an object with the user and host attributes. The inverse of normalize_account.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "account",
+				Description: "Audit rule username in the user@host format.",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: accountAttributeTypes,
+		},
+	}
+}
+
+func (f *parseAccountFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var account string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &account))
+	if resp.Error != nil {
+		return
+	}
+
+	user, host := splitRuleAccount(account)
+	if user == "" {
+		resp.Error = function.NewArgumentFuncError(0, "account must contain a user")
+		return
+	}
+
+	result, diags := types.ObjectValue(accountAttributeTypes, map[string]attr.Value{
+		"user": types.StringValue(user),
+		"host": types.StringValue(host),
+	})
+	resp.Error = function.FuncErrorFromDiags(ctx, diags)
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, result))
+}
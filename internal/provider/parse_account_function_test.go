@@ -0,0 +1,42 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestParseAccountFunction(t *testing.T) {
+	tests := []struct {
+		account string
+		user    string
+		host    string
+	}{
+		{"app@%", "app", "%"},
+		{"app", "app", "*"},
+		{"*", "*", "*"},
+		{"`app.user`@`10.%`", "app.user", "10.%"},
+		{"'app'@'localhost'", "app", "localhost"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.account, func(t *testing.T) {
+			got, err := testRunFunction(t, NewParseAccountFunction(), types.ObjectUnknown(accountAttributeTypes), types.StringValue(tt.account))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			attributes := got.(types.Object).Attributes()
+			if attributes["user"].(types.String).ValueString() != tt.user || attributes["host"].(types.String).ValueString() != tt.host {
+				t.Errorf("expected %q and %q, got %v", tt.user, tt.host, attributes)
+			}
+		})
+	}
+
+	if _, err := testRunFunction(t, NewParseAccountFunction(), types.ObjectUnknown(accountAttributeTypes), types.StringValue("@%")); err == nil {
+		t.Error("expected an account without user to fail")
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// defaultPasswordCommandTimeout is how long password_command may run when
+// password_command_timeout isn't set.
+const defaultPasswordCommandTimeout = 30 * time.Second
+
+// readPasswordFile returns the contents of the file without the trailing
+// newline that most editors and secret managers add.
+func readPasswordFile(path string) (string, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(contents), "\r\n"), nil
+}
+
+// runPasswordCommand runs a credential helper and returns the first line it
+// writes to stdout. The errors never include the command output since it
+// might contain the secret.
+func runPasswordCommand(ctx context.Context, args []string, timeout time.Duration) (string, error) {
+	if len(args) == 0 || args[0] == "" {
+		return "", errors.New("password command must not be empty")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = &stdout
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("%s timed out after %s", args[0], timeout)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w", args[0], err)
+	}
+
+	password, _, _ := strings.Cut(stdout.String(), "\n")
+	password = strings.TrimRight(password, "\r")
+	if password == "" {
+		return "", fmt.Errorf("%s did not print a password", args[0])
+	}
+
+	return password, nil
+}
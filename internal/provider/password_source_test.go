@@ -0,0 +1,68 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadPasswordFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(name, []byte("s3cret\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	password, err := readPasswordFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if password != "s3cret" {
+		t.Errorf("expected the trailing newline to be trimmed, got %q", password)
+	}
+
+	if _, err := readPasswordFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected a missing file to fail")
+	}
+}
+
+func TestRunPasswordCommand(t *testing.T) {
+	ctx := context.Background()
+
+	password, err := runPasswordCommand(ctx, []string{"printf", "s3cret\nignored\n"}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if password != "s3cret" {
+		t.Errorf("expected the first line of the output, got %q", password)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		timeout time.Duration
+		err     string
+	}{
+		{"empty", nil, time.Second, "password command must not be empty"},
+		{"failure", []string{"sh", "-c", "echo s3cret; exit 3"}, time.Second, "sh failed: exit status 3"},
+		{"no output", []string{"true"}, time.Second, "true did not print a password"},
+		{"timeout", []string{"sleep", "5"}, 50 * time.Millisecond, "sleep timed out after 50ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runPasswordCommand(ctx, tt.args, tt.timeout)
+			if err == nil || err.Error() != tt.err {
+				t.Fatalf("expected %q, got %v", tt.err, err)
+			}
+			if strings.Contains(err.Error(), "s3cret") {
+				t.Errorf("the error leaks the command output: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lib/pq"
+)
+
+// pgauditEnabledFlag is the database flag that has to be on before the
+// pgaudit extension can be created.
+const pgauditEnabledFlag = "cloudsql.enable_pgaudit"
+
+const showPgauditEnabled = "SHOW " + pgauditEnabledFlag
+
+const readPgauditExtension = "SELECT extversion FROM pg_extension WHERE extname = 'pgaudit'"
+
+const readPgauditDefaultVersion = "SELECT default_version FROM pg_available_extensions WHERE name = 'pgaudit'"
+
+const createPgauditExtension = "CREATE EXTENSION IF NOT EXISTS pgaudit"
+
+const updatePgauditExtension = "ALTER EXTENSION pgaudit UPDATE"
+
+const dropPgauditExtension = "DROP EXTENSION IF EXISTS pgaudit"
+
+// pgUndefinedObject is the SQLSTATE returned by SHOW for unknown settings.
+const pgUndefinedObject = "42704"
+
+// postgreSQLDatabase returns the connection pool to a database of one of the
+// configured instances.
+func (c CloudSqlClientAndConfig) postgreSQLDatabase(ctx context.Context, instance types.String, database string) (*sql.DB, error) {
+	conn, err := c.connection(ctx, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	return conn.database(database)
+}
+
+// checkPgauditEnabled returns an error explaining how to turn pgaudit on when
+// the cloudsql.enable_pgaudit flag is off.
+func checkPgauditEnabled(ctx context.Context, conn *sql.DB) error {
+	var value string
+	err := conn.QueryRowContext(ctx, showPgauditEnabled).Scan(&value)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pgUndefinedObject {
+		return fmt.Errorf("the %s flag is not available, pgaudit can only be managed on cloudsql instances", pgauditEnabledFlag)
+	} else if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(value, "on") {
+		return fmt.Errorf("the %s database flag is %q, set it to on (which restarts the instance) before creating the pgaudit extension", pgauditEnabledFlag, value)
+	}
+
+	return nil
+}
+
+// readPgauditVersion returns the installed version of the pgaudit extension
+// in the database, or an empty string when it isn't installed.
+func readPgauditVersion(ctx context.Context, conn *sql.DB) (string, error) {
+	var version string
+	err := conn.QueryRowContext(ctx, readPgauditExtension).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+
+	return version, err
+}
+
+// readPgauditRoleSettings reads the settings of a single role and database
+// scope, an empty role or database stands for all of them like setrole and
+// setdatabase 0 do.
+const readPgauditRoleSettings = `SELECT s.setconfig FROM pg_db_role_setting s
+LEFT JOIN pg_roles r ON r.oid = s.setrole
+LEFT JOIN pg_database d ON d.oid = s.setdatabase
+WHERE COALESCE(r.rolname, '') = $1 AND COALESCE(d.datname, '') = $2`
+
+// pgauditParameters are the pgaudit settings that can be set per role and
+// database.
+var pgauditParameters = []string{
+	"pgaudit.log",
+	"pgaudit.log_catalog",
+	"pgaudit.log_parameter",
+	"pgaudit.log_relation",
+	"pgaudit.log_statement_once",
+	"pgaudit.log_level",
+}
+
+// pgauditLogClasses are the statement classes accepted by pgaudit.log, each
+// of them can be excluded with a - prefix.
+var pgauditLogClasses = map[string]bool{
+	"read":     true,
+	"write":    true,
+	"function": true,
+	"role":     true,
+	"ddl":      true,
+	"misc":     true,
+	"misc_set": true,
+	"all":      true,
+	"none":     true,
+}
+
+// pgauditLogLevels are the levels accepted by pgaudit.log_level.
+var pgauditLogLevels = map[string]bool{
+	"debug1":  true,
+	"debug2":  true,
+	"debug3":  true,
+	"debug4":  true,
+	"debug5":  true,
+	"info":    true,
+	"notice":  true,
+	"warning": true,
+	"log":     true,
+}
+
+// invalidPgauditLogClasses returns the entries of a pgaudit.log value that
+// aren't statement classes.
+func invalidPgauditLogClasses(value string) []string {
+	var invalid []string
+	for _, class := range strings.Split(value, ",") {
+		class = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(class)), "-")
+		if !pgauditLogClasses[class] {
+			invalid = append(invalid, class)
+		}
+	}
+
+	return invalid
+}
+
+// readPgauditSettings returns the pgaudit settings stored for a role and
+// database scope.
+func readPgauditSettings(ctx context.Context, conn *sql.DB, role, database string) (map[string]string, error) {
+	var config []string
+	err := conn.QueryRowContext(ctx, readPgauditRoleSettings, role, database).Scan(pq.Array(&config))
+	if errors.Is(err, sql.ErrNoRows) {
+		return map[string]string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return pgauditSettingsFromConfig(config), nil
+}
+
+// pgauditSettingsFromConfig picks the pgaudit settings out of a setconfig
+// array of name=value entries.
+func pgauditSettingsFromConfig(config []string) map[string]string {
+	settings := make(map[string]string)
+	for _, entry := range config {
+		name, value, ok := strings.Cut(entry, "=")
+		if ok && strings.HasPrefix(name, "pgaudit.") {
+			settings[name] = value
+		}
+	}
+
+	return settings
+}
+
+// pgauditAlterStatement returns the ALTER statement for the settings of a
+// role, a database or a role in a database.
+func pgauditAlterStatement(role, database string) string {
+	switch {
+	case role != "" && database != "":
+		return "ALTER ROLE " + pq.QuoteIdentifier(role) + " IN DATABASE " + pq.QuoteIdentifier(database)
+	case role != "":
+		return "ALTER ROLE " + pq.QuoteIdentifier(role)
+	default:
+		return "ALTER DATABASE " + pq.QuoteIdentifier(database)
+	}
+}
+
+// parsePgBool parses the spellings postgresql accepts for boolean settings.
+func parsePgBool(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "on", "true", "yes", "1", "t", "y":
+		return true, true
+	case "off", "false", "no", "0", "f", "n":
+		return false, true
+	}
+
+	return false, false
+}
+
+// readCurrentSetting reads the value of a setting in effect for the
+// session, NULL when it isn't set.
+const readCurrentSetting = "SELECT current_setting($1, true)"
+
+const readRoleTableGrants = `SELECT privilege_type FROM information_schema.role_table_grants
+WHERE grantee = $1 AND table_schema = $2 AND table_name = $3`
+
+const readRoleColumnGrants = `SELECT column_name, privilege_type FROM information_schema.column_privileges
+WHERE grantee = $1 AND table_schema = $2 AND table_name = $3`
+
+// pgauditObjectPrivileges are the privileges that enable object audit
+// logging of the matching statements, DELETE can't be granted on columns.
+var pgauditObjectPrivileges = map[string]bool{
+	"SELECT": true,
+	"INSERT": true,
+	"UPDATE": true,
+	"DELETE": true,
+}
+
+// readPgauditObjectRole returns the pgaudit.role setting of the database, or
+// an empty string if it isn't set.
+func readPgauditObjectRole(ctx context.Context, conn *sql.DB) (string, error) {
+	var role sql.NullString
+	err := conn.QueryRowContext(ctx, readCurrentSetting, "pgaudit.role").Scan(&role)
+
+	return role.String, err
+}
+
+const readCurrentDatabase = "SELECT current_database()"
+
+// readAllPgauditRoleSettings reads the settings of every role and database
+// scope, like readPgauditRoleSettings an empty name stands for all of them.
+const readAllPgauditRoleSettings = `SELECT COALESCE(r.rolname, ''), COALESCE(d.datname, ''), s.setconfig FROM pg_db_role_setting s
+LEFT JOIN pg_roles r ON r.oid = s.setrole
+LEFT JOIN pg_database d ON d.oid = s.setdatabase
+ORDER BY 1, 2`
+
+const readAllRoleTableGrants = `SELECT table_schema, table_name, privilege_type FROM information_schema.role_table_grants
+WHERE grantee = $1 ORDER BY 1, 2, 3`
+
+const readAllRoleColumnGrants = `SELECT table_schema, table_name, column_name, privilege_type FROM information_schema.column_privileges
+WHERE grantee = $1 ORDER BY 1, 2, 3, 4`
@@ -0,0 +1,324 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lib/pq"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &pgauditExtensionResource{}
+	_ resource.ResourceWithConfigure   = &pgauditExtensionResource{}
+	_ resource.ResourceWithImportState = &pgauditExtensionResource{}
+)
+
+func NewPgauditExtensionResource() resource.Resource {
+	return &pgauditExtensionResource{}
+}
+
+// pgauditExtensionResource manages the pgaudit extension of a database,
+// extensions are installed per database in postgresql.
+type pgauditExtensionResource struct {
+	client CloudSqlClientAndConfig
+}
+
+type pgauditExtensionResourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	Instance       types.String `tfsdk:"instance"`
+	Database       types.String `tfsdk:"database"`
+	Version        types.String `tfsdk:"version"`
+	DefaultVersion types.String `tfsdk:"default_version"`
+}
+
+func (r *pgauditExtensionResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_pgaudit_extension"
+}
+
+func (r *pgauditExtensionResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"instance": schema.StringAttribute{
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"database": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"version": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"default_version": schema.StringAttribute{
+				Computed: true,
+			},
+		},
+	}
+}
+
+func (r *pgauditExtensionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan pgauditExtensionResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	conn, err := r.client.postgreSQLDatabase(ctx, plan.Instance, plan.Database.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+
+	if err := checkPgauditEnabled(ctx, conn); err != nil {
+		resp.Diagnostics.AddError(
+			"pgAudit is not enabled",
+			err.Error(),
+		)
+		return
+	}
+
+	query := createPgauditExtension
+	if !plan.Version.IsNull() && !plan.Version.IsUnknown() {
+		query += " VERSION " + pq.QuoteLiteral(plan.Version.ValueString())
+	}
+
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to create pgaudit extension",
+			err.Error(),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(r.readInstalled(ctx, conn, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *pgauditExtensionResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state pgauditExtensionResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the extension can't be refreshed until the provider configuration is
+	// known, keep the current state until then
+	if r.client.unknown {
+		return
+	}
+
+	conn, err := r.client.postgreSQLDatabase(ctx, state.Instance, state.Database.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+
+	installed, diags := r.read(ctx, conn, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	} else if !installed {
+		// the extension was dropped outside of terraform
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *pgauditExtensionResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state pgauditExtensionResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	conn, err := r.client.postgreSQLDatabase(ctx, plan.Instance, plan.Database.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+
+	// only the version can change in place
+	if !plan.Version.IsUnknown() && !plan.Version.Equal(state.Version) {
+		query := updatePgauditExtension + " TO " + pq.QuoteLiteral(plan.Version.ValueString())
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to update pgaudit extension",
+				err.Error(),
+			)
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(r.readInstalled(ctx, conn, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags := resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *pgauditExtensionResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state pgauditExtensionResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	conn, err := r.client.postgreSQLDatabase(ctx, state.Instance, state.Database.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+
+	if _, err := conn.ExecContext(ctx, dropPgauditExtension); err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to drop pgaudit extension",
+			err.Error(),
+		)
+		return
+	}
+}
+
+// read sets the computed attributes from the installed extension, it
+// reports whether the extension is installed at all.
+func (r *pgauditExtensionResource) read(ctx context.Context, conn *sql.DB, m *pgauditExtensionResourceModel) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	version, err := readPgauditVersion(ctx, conn)
+	if err != nil {
+		diags.AddError(
+			"Error reading pgaudit extension",
+			err.Error(),
+		)
+		return false, diags
+	} else if version == "" {
+		return false, diags
+	}
+
+	var defaultVersion string
+	err = conn.QueryRowContext(ctx, readPgauditDefaultVersion).Scan(&defaultVersion)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		diags.AddError(
+			"Error reading pgaudit extension",
+			err.Error(),
+		)
+		return false, diags
+	}
+
+	m.ID = m.Database
+	if !m.Instance.IsNull() {
+		m.ID = types.StringValue(m.Instance.ValueString() + "/" + m.Database.ValueString())
+	}
+	m.Version = types.StringValue(version)
+	m.DefaultVersion = types.StringValue(defaultVersion)
+
+	return true, diags
+}
+
+// readInstalled is read for create and update, where the extension has to
+// exist afterwards.
+func (r *pgauditExtensionResource) readInstalled(ctx context.Context, conn *sql.DB, m *pgauditExtensionResourceModel) diag.Diagnostics {
+	installed, diags := r.read(ctx, conn, m)
+	if !installed && !diags.HasError() {
+		diags.AddError(
+			"Error reading pgaudit extension",
+			fmt.Sprintf("The pgaudit extension is not installed in database %q.", m.Database.ValueString()),
+		)
+	}
+
+	return diags
+}
+
+func (r *pgauditExtensionResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(CloudSqlClientAndConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *sql.DB got %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !client.supportsEngine("postgresql") {
+		resp.Diagnostics.AddError(
+			"Must use postgresql engine for pgaudit types",
+			fmt.Sprintf("Configured engine is %q", client.engine),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// ImportState accepts either the database name or <instance>/<database> for
+// databases on one of the provider instances.
+func (r *pgauditExtensionResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	instance, database, found := cutLast(req.ID, "/")
+	if !found {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("database"), req.ID)...)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("instance"), instance)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("database"), database)...)
+}
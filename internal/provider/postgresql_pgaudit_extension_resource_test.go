@@ -0,0 +1,121 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"terraform-provider-cloudsql-auditlog/internal/cloudsqlfake"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccPgauditExtensionResource(t *testing.T) {
+	server, providerConfig := newTestPostgresInstance(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckPgauditVersion(server, "app", ""),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_pgaudit_extension" "test" {
+  database = "app"
+  version  = "1.6.2"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsql-auditlog_pgaudit_extension.test", "id", "app"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_pgaudit_extension.test", "version", "1.6.2"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_pgaudit_extension.test", "default_version", "1.7"),
+					testAccCheckPgauditVersion(server, "app", "1.6.2"),
+				),
+			},
+			{
+				ResourceName:      "cloudsql-auditlog_pgaudit_extension.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_pgaudit_extension" "test" {
+  database = "app"
+  version  = "1.7"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsql-auditlog_pgaudit_extension.test", "version", "1.7"),
+					testAccCheckPgauditVersion(server, "app", "1.7"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPgauditExtensionResourceDisabled(t *testing.T) {
+	server, providerConfig := newTestPostgresInstance(t)
+	server.Flags["cloudsql.enable_pgaudit"] = "off"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_pgaudit_extension" "test" {
+  database = "app"
+}
+`,
+				ExpectError: regexp.MustCompile(`cloudsql.enable_pgaudit database flag is "off"`),
+			},
+		},
+	})
+}
+
+func TestAccPgauditExtensionResourceDropped(t *testing.T) {
+	server, providerConfig := newTestPostgresInstance(t)
+
+	config := providerConfig + `
+resource "cloudsql-auditlog_pgaudit_extension" "test" {
+  database = "app"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  testAccCheckPgauditVersion(server, "app", "1.7"),
+			},
+			{
+				// dropping the extension outside of terraform recreates it
+				PreConfig: func() {
+					if _, err := server.DB("app").Exec("DROP EXTENSION IF EXISTS pgaudit"); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config,
+				Check:  testAccCheckPgauditVersion(server, "app", "1.7"),
+			},
+		},
+	})
+}
+
+// testAccCheckPgauditVersion checks the installed pgaudit version, an empty
+// version checks that the extension isn't installed.
+func testAccCheckPgauditVersion(server *cloudsqlfake.PostgresServer, database, want string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		version, ok := server.Pgaudit(database)
+		if want == "" && ok {
+			return fmt.Errorf("expected pgaudit not to be installed in %s, got version %s", database, version)
+		} else if want != "" && version != want {
+			return fmt.Errorf("expected pgaudit %s in %s, got %q", want, database, version)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,595 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lib/pq"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &pgauditObjectAuditResource{}
+	_ resource.ResourceWithConfigure      = &pgauditObjectAuditResource{}
+	_ resource.ResourceWithImportState    = &pgauditObjectAuditResource{}
+	_ resource.ResourceWithValidateConfig = &pgauditObjectAuditResource{}
+)
+
+func NewPgauditObjectAuditResource() resource.Resource {
+	return &pgauditObjectAuditResource{}
+}
+
+// pgauditObjectAuditResource enables pgaudit object audit logging for a
+// table by granting privileges on it to the pgaudit.role auditor role,
+// statements are logged when the role holds the privilege they use.
+type pgauditObjectAuditResource struct {
+	client CloudSqlClientAndConfig
+}
+
+type pgauditObjectAuditResourceModel struct {
+	ID         types.String `tfsdk:"id"`
+	Instance   types.String `tfsdk:"instance"`
+	Database   types.String `tfsdk:"database"`
+	Role       types.String `tfsdk:"role"`
+	Schema     types.String `tfsdk:"schema"`
+	Table      types.String `tfsdk:"table"`
+	Columns    types.Set    `tfsdk:"columns"`
+	Privileges types.Set    `tfsdk:"privileges"`
+}
+
+// objectGrant is a privilege granted on the table, or on one of its columns.
+type objectGrant struct {
+	privilege string
+	column    string
+}
+
+func (r *pgauditObjectAuditResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_pgaudit_object_audit"
+}
+
+func (r *pgauditObjectAuditResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"instance": schema.StringAttribute{
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"database": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"role": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"schema": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"table": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"columns": schema.SetAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"privileges": schema.SetAttribute{
+				Required:    true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+func (r *pgauditObjectAuditResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config pgauditObjectAuditResourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.Privileges.IsUnknown() || config.Columns.IsUnknown() {
+		return
+	}
+
+	var privileges, columns []string
+	resp.Diagnostics.Append(config.Privileges.ElementsAs(ctx, &privileges, false)...)
+	if !config.Columns.IsNull() {
+		resp.Diagnostics.Append(config.Columns.ElementsAs(ctx, &columns, false)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if len(privileges) == 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("privileges"),
+			"Missing privileges",
+			"At least one of SELECT, INSERT, UPDATE and DELETE must be granted.",
+		)
+	}
+
+	for _, privilege := range privileges {
+		if !pgauditObjectPrivileges[privilege] {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("privileges"),
+				"Invalid privilege",
+				fmt.Sprintf("Invalid privilege %q, allowed values: SELECT, INSERT, UPDATE, DELETE.", privilege),
+			)
+		} else if privilege == "DELETE" && !config.Columns.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("privileges"),
+				"Invalid column privilege",
+				"DELETE can only be granted on the whole table, remove columns or the DELETE privilege.",
+			)
+		}
+	}
+
+	if !config.Columns.IsNull() && len(columns) == 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("columns"),
+			"Missing columns",
+			"Leave columns out to audit the whole table.",
+		)
+	}
+}
+
+func (r *pgauditObjectAuditResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan pgauditObjectAuditResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	conn, err := r.client.postgreSQLDatabase(ctx, plan.Instance, plan.Database.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+
+	auditRole, err := readPgauditObjectRole(ctx, conn)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read pgaudit.role",
+			err.Error(),
+		)
+		return
+	}
+
+	if plan.Role.IsUnknown() || plan.Role.IsNull() {
+		if auditRole == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("role"),
+				"Missing auditor role",
+				fmt.Sprintf("pgaudit.role is not set in database %q, set it with ALTER DATABASE %s SET pgaudit.role = '<role>' or configure role.",
+					plan.Database.ValueString(), pq.QuoteIdentifier(plan.Database.ValueString())),
+			)
+			return
+		}
+		plan.Role = types.StringValue(auditRole)
+	} else if auditRole != plan.Role.ValueString() {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("role"),
+			"Role is not the pgaudit role",
+			fmt.Sprintf("pgaudit.role is %q in database %q, the grants to %q don't enable object audit logging until it is changed.",
+				auditRole, plan.Database.ValueString(), plan.Role.ValueString()),
+		)
+	}
+
+	grants, diags := plan.grants(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.changeGrants(ctx, conn, plan, "GRANT", grants)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = types.StringValue(plan.id())
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *pgauditObjectAuditResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state pgauditObjectAuditResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the grants can't be refreshed until the provider configuration is
+	// known, keep the current state until then
+	if r.client.unknown {
+		return
+	}
+
+	conn, err := r.client.postgreSQLDatabase(ctx, state.Instance, state.Database.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+
+	grants, err := readObjectGrants(ctx, conn, state.Role.ValueString(), state.Schema.ValueString(), state.Table.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read grants",
+			err.Error(),
+		)
+		return
+	}
+
+	// the grants were revoked, or the table dropped, outside of terraform
+	if len(grants) == 0 {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	resp.Diagnostics.Append(state.setGrants(ctx, grants)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.ID = types.StringValue(state.id())
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *pgauditObjectAuditResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state pgauditObjectAuditResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	conn, err := r.client.postgreSQLDatabase(ctx, plan.Instance, plan.Database.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+
+	planned, diags := plan.grants(ctx)
+	resp.Diagnostics.Append(diags...)
+	current, diags := state.grants(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// only the differences are applied so that auditing doesn't stop for
+	// the privileges that are kept
+	var revoke, grant []objectGrant
+	for _, g := range current {
+		if !slices.Contains(planned, g) {
+			revoke = append(revoke, g)
+		}
+	}
+	for _, g := range planned {
+		if !slices.Contains(current, g) {
+			grant = append(grant, g)
+		}
+	}
+
+	resp.Diagnostics.Append(r.changeGrants(ctx, conn, plan, "REVOKE", revoke)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.changeGrants(ctx, conn, plan, "GRANT", grant)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = types.StringValue(plan.id())
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *pgauditObjectAuditResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state pgauditObjectAuditResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	conn, err := r.client.postgreSQLDatabase(ctx, state.Instance, state.Database.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+
+	grants, diags := state.grants(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.changeGrants(ctx, conn, state, "REVOKE", grants)...)
+}
+
+// changeGrants runs GRANT or REVOKE for the grants of the model.
+func (r *pgauditObjectAuditResource) changeGrants(ctx context.Context, conn *sql.DB, m pgauditObjectAuditResourceModel, verb string, grants []objectGrant) diag.Diagnostics {
+	return changeObjectGrants(ctx, conn, verb, m.Schema.ValueString(), m.Table.ValueString(), m.Role.ValueString(), grants)
+}
+
+// changeObjectGrants runs GRANT or REVOKE for the grants on a table, one
+// statement per privilege.
+func changeObjectGrants(ctx context.Context, conn *sql.DB, verb, schema, table, role string, grants []objectGrant) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	columns := make(map[string][]string)
+	for _, g := range grants {
+		columns[g.privilege] = append(columns[g.privilege], g.column)
+	}
+
+	privileges := make([]string, 0, len(columns))
+	for privilege := range columns {
+		privileges = append(privileges, privilege)
+	}
+	sort.Strings(privileges)
+
+	target := " TO "
+	if verb == "REVOKE" {
+		target = " FROM "
+	}
+
+	for _, privilege := range privileges {
+		query := verb + " " + privilege
+		if quoted := quoteColumns(columns[privilege]); quoted != "" {
+			query += " (" + quoted + ")"
+		}
+		query += " ON " + pq.QuoteIdentifier(schema) + "." + pq.QuoteIdentifier(table) + target + pq.QuoteIdentifier(role)
+
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			diags.AddError(
+				fmt.Sprintf("Unable to %s %s", strings.ToLower(verb), privilege),
+				err.Error(),
+			)
+			return diags
+		}
+	}
+
+	return diags
+}
+
+// quoteColumns returns the quoted column list, table level grants have no
+// columns.
+func quoteColumns(columns []string) string {
+	var quoted []string
+	for _, column := range columns {
+		if column != "" {
+			quoted = append(quoted, pq.QuoteIdentifier(column))
+		}
+	}
+	sort.Strings(quoted)
+
+	return strings.Join(quoted, ", ")
+}
+
+// readObjectGrants returns the privileges the role holds on the table. Table
+// level privileges are listed in column_privileges for every column too, so
+// the column grants only include the other privileges.
+func readObjectGrants(ctx context.Context, conn *sql.DB, role, schema, table string) ([]objectGrant, error) {
+	var grants []objectGrant
+	tableLevel := make(map[string]bool)
+
+	rows, err := conn.QueryContext(ctx, readRoleTableGrants, role, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var privilege string
+		if err := rows.Scan(&privilege); err != nil {
+			return nil, err
+		}
+
+		if pgauditObjectPrivileges[privilege] {
+			tableLevel[privilege] = true
+			grants = append(grants, objectGrant{privilege: privilege})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	columnRows, err := conn.QueryContext(ctx, readRoleColumnGrants, role, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer columnRows.Close()
+
+	for columnRows.Next() {
+		var column, privilege string
+		if err := columnRows.Scan(&column, &privilege); err != nil {
+			return nil, err
+		}
+
+		if pgauditObjectPrivileges[privilege] && !tableLevel[privilege] {
+			grants = append(grants, objectGrant{privilege: privilege, column: column})
+		}
+	}
+
+	return grants, columnRows.Err()
+}
+
+// grants returns the grants described by the model, one per privilege and
+// column.
+func (m pgauditObjectAuditResourceModel) grants(ctx context.Context) ([]objectGrant, diag.Diagnostics) {
+	var privileges []string
+	columns := []string{""}
+
+	diags := m.Privileges.ElementsAs(ctx, &privileges, false)
+	if !m.Columns.IsNull() && !m.Columns.IsUnknown() {
+		columns = nil
+		diags.Append(m.Columns.ElementsAs(ctx, &columns, false)...)
+	}
+
+	var grants []objectGrant
+	for _, privilege := range privileges {
+		for _, column := range columns {
+			grants = append(grants, objectGrant{privilege: privilege, column: column})
+		}
+	}
+
+	return grants, diags
+}
+
+// setGrants sets the privileges and columns from the grants read from the
+// instance. Table level grants are preferred, the columns are only set when
+// the role has no privilege on the whole table. Columns missing one of the
+// privileges are left out so that the next apply grants it again.
+func (m *pgauditObjectAuditResourceModel) setGrants(ctx context.Context, grants []objectGrant) diag.Diagnostics {
+	privileges := make(map[string]bool)
+	columns := make(map[string]map[string]bool)
+
+	tableLevel := slices.ContainsFunc(grants, func(g objectGrant) bool { return g.column == "" })
+
+	for _, g := range grants {
+		if tableLevel && g.column == "" {
+			privileges[g.privilege] = true
+		} else if !tableLevel {
+			privileges[g.privilege] = true
+			if columns[g.column] == nil {
+				columns[g.column] = make(map[string]bool)
+			}
+			columns[g.column][g.privilege] = true
+		}
+	}
+
+	var diags diag.Diagnostics
+	m.Privileges, diags = types.SetValueFrom(ctx, types.StringType, slices.Sorted(maps.Keys(privileges)))
+
+	m.Columns = types.SetNull(types.StringType)
+	if !tableLevel {
+		complete := []string{}
+		for column, granted := range columns {
+			if len(granted) == len(privileges) {
+				complete = append(complete, column)
+			}
+		}
+		sort.Strings(complete)
+
+		var d diag.Diagnostics
+		m.Columns, d = types.SetValueFrom(ctx, types.StringType, complete)
+		diags.Append(d...)
+	}
+
+	return diags
+}
+
+// id returns [<instance>/]<database>/<schema>/<table>/<role>.
+func (m pgauditObjectAuditResourceModel) id() string {
+	id := strings.Join([]string{m.Database.ValueString(), m.Schema.ValueString(), m.Table.ValueString(), m.Role.ValueString()}, "/")
+	if !m.Instance.IsNull() {
+		id = m.Instance.ValueString() + "/" + id
+	}
+
+	return id
+}
+
+func (r *pgauditObjectAuditResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(CloudSqlClientAndConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *sql.DB got %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !client.supportsEngine("postgresql") {
+		resp.Diagnostics.AddError(
+			"Must use postgresql engine for pgaudit types",
+			fmt.Sprintf("Configured engine is %q", client.engine),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// ImportState accepts [<instance>/]<database>/<schema>/<table>/<role>, the
+// privileges and columns are read from the grants.
+func (r *pgauditObjectAuditResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts := strings.Split(req.ID, "/")
+	if len(parts) == 5 {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("instance"), parts[0])...)
+		parts = parts[1:]
+	}
+
+	if len(parts) != 4 || slices.Contains(parts, "") {
+		resp.Diagnostics.AddError(
+			"Invalid import id",
+			fmt.Sprintf("Expected [<instance>/]<database>/<schema>/<table>/<role>, got %q.", req.ID),
+		)
+		return
+	}
+
+	for i, name := range []string{"database", "schema", "table", "role"} {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(name), parts[i])...)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"terraform-provider-cloudsql-auditlog/internal/cloudsqlfake"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccPgauditObjectAuditResource(t *testing.T) {
+	server, providerConfig := newTestPostgresInstance(t)
+	server.SetSetting(cloudsqlfake.SettingScope{Database: "app"}, "pgaudit.role", "auditor")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckObjectGrants(server, ""),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_pgaudit_object_audit" "test" {
+  database   = "app"
+  schema     = "public"
+  table      = "orders"
+  privileges = ["SELECT", "DELETE"]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsql-auditlog_pgaudit_object_audit.test", "id", "app/public/orders/auditor"),
+					resource.TestCheckResourceAttr("cloudsql-auditlog_pgaudit_object_audit.test", "role", "auditor"),
+					testAccCheckObjectGrants(server, "DELETE SELECT"),
+				),
+			},
+			{
+				ResourceName:      "cloudsql-auditlog_pgaudit_object_audit.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				// switching to column auditing revokes the table privileges
+				Config: providerConfig + `
+resource "cloudsql-auditlog_pgaudit_object_audit" "test" {
+  database   = "app"
+  schema     = "public"
+  table      = "orders"
+  columns    = ["customer", "total"]
+  privileges = ["SELECT", "UPDATE"]
+}
+`,
+				Check: testAccCheckObjectGrants(server, "SELECT:customer SELECT:total UPDATE:customer UPDATE:total"),
+			},
+			{
+				ResourceName:      "cloudsql-auditlog_pgaudit_object_audit.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				// grants revoked outside of terraform are granted again
+				PreConfig: func() {
+					server.RevokeGrant(cloudsqlfake.Grant{
+						Database: "app", Schema: "public", Table: "orders", Role: "auditor", Privilege: "UPDATE", Column: "total",
+					})
+				},
+				Config: providerConfig + `
+resource "cloudsql-auditlog_pgaudit_object_audit" "test" {
+  database   = "app"
+  schema     = "public"
+  table      = "orders"
+  columns    = ["customer", "total"]
+  privileges = ["SELECT", "UPDATE"]
+}
+`,
+				Check: testAccCheckObjectGrants(server, "SELECT:customer SELECT:total UPDATE:customer UPDATE:total"),
+			},
+		},
+	})
+}
+
+func TestAccPgauditObjectAuditResourceInvalid(t *testing.T) {
+	_, providerConfig := newTestPostgresInstance(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_pgaudit_object_audit" "test" {
+  database   = "app"
+  schema     = "public"
+  table      = "orders"
+  columns    = ["total"]
+  privileges = ["DELETE"]
+}
+`,
+				ExpectError: regexp.MustCompile(`DELETE can only be granted on the whole table`),
+			},
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_pgaudit_object_audit" "test" {
+  database   = "app"
+  schema     = "public"
+  table      = "orders"
+  privileges = ["TRUNCATE"]
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid privilege "TRUNCATE"`),
+			},
+			{
+				// without pgaudit.role the role has to be configured
+				Config: providerConfig + `
+resource "cloudsql-auditlog_pgaudit_object_audit" "test" {
+  database   = "app"
+  schema     = "public"
+  table      = "orders"
+  privileges = ["SELECT"]
+}
+`,
+				ExpectError: regexp.MustCompile(`pgaudit.role is not set in database "app", set it with ALTER DATABASE\s+"app"\s+SET\s+pgaudit.role`),
+			},
+		},
+	})
+}
+
+// testAccCheckObjectGrants compares the privileges of the auditor role on
+// the orders table to a list of privilege[:column] entries.
+func testAccCheckObjectGrants(server *cloudsqlfake.PostgresServer, expected string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		var grants []string
+		for _, grant := range server.Grants("app", "public", "orders", "auditor") {
+			if grant.Column == "" {
+				grants = append(grants, grant.Privilege)
+			} else {
+				grants = append(grants, grant.Privilege+":"+grant.Column)
+			}
+		}
+
+		if got := strings.Join(grants, " "); got != expected {
+			return fmt.Errorf("expected grants %q, got %q", expected, got)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,468 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lib/pq"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &pgauditSettingResource{}
+	_ resource.ResourceWithConfigure      = &pgauditSettingResource{}
+	_ resource.ResourceWithImportState    = &pgauditSettingResource{}
+	_ resource.ResourceWithValidateConfig = &pgauditSettingResource{}
+)
+
+func NewPgauditSettingResource() resource.Resource {
+	return &pgauditSettingResource{}
+}
+
+// pgauditSettingResource manages the pgaudit settings of a role, a database
+// or a role in a database with ALTER ROLE and ALTER DATABASE. The resource
+// owns every pgaudit setting of its scope, the ones that aren't configured
+// are reset.
+type pgauditSettingResource struct {
+	client CloudSqlClientAndConfig
+}
+
+type pgauditSettingResourceModel struct {
+	ID       types.String `tfsdk:"id"`
+	Instance types.String `tfsdk:"instance"`
+	Role     types.String `tfsdk:"role"`
+	Database types.String `tfsdk:"database"`
+
+	Log              types.String `tfsdk:"log"`
+	LogCatalog       types.Bool   `tfsdk:"log_catalog"`
+	LogParameter     types.Bool   `tfsdk:"log_parameter"`
+	LogRelation      types.Bool   `tfsdk:"log_relation"`
+	LogStatementOnce types.Bool   `tfsdk:"log_statement_once"`
+	LogLevel         types.String `tfsdk:"log_level"`
+}
+
+func (r *pgauditSettingResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_pgaudit_setting"
+}
+
+func (r *pgauditSettingResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"instance": schema.StringAttribute{
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"role": schema.StringAttribute{
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"database": schema.StringAttribute{
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"log": schema.StringAttribute{
+				Optional: true,
+			},
+			"log_catalog": schema.BoolAttribute{
+				Optional: true,
+			},
+			"log_parameter": schema.BoolAttribute{
+				Optional: true,
+			},
+			"log_relation": schema.BoolAttribute{
+				Optional: true,
+			},
+			"log_statement_once": schema.BoolAttribute{
+				Optional: true,
+			},
+			"log_level": schema.StringAttribute{
+				Optional: true,
+			},
+		},
+	}
+}
+
+func (r *pgauditSettingResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config pgauditSettingResourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.Role.IsNull() && config.Database.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("role"),
+			"Missing pgaudit setting scope",
+			"At least one of role and database must be set.",
+		)
+	}
+
+	if config.Log.IsNull() && config.LogCatalog.IsNull() && config.LogParameter.IsNull() &&
+		config.LogRelation.IsNull() && config.LogStatementOnce.IsNull() && config.LogLevel.IsNull() {
+		resp.Diagnostics.AddError(
+			"Missing pgaudit settings",
+			"At least one of log, log_catalog, log_parameter, log_relation, log_statement_once and log_level must be set.",
+		)
+	}
+
+	if !config.Log.IsNull() && !config.Log.IsUnknown() {
+		if invalid := invalidPgauditLogClasses(config.Log.ValueString()); len(invalid) > 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("log"),
+				"Invalid pgaudit.log classes",
+				fmt.Sprintf("Unknown classes %s, allowed values: read, write, function, role, ddl, misc, misc_set, all, none (optionally prefixed with -).",
+					strings.Join(invalid, ", ")),
+			)
+		}
+	}
+
+	if !config.LogLevel.IsNull() && !config.LogLevel.IsUnknown() && !pgauditLogLevels[strings.ToLower(config.LogLevel.ValueString())] {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("log_level"),
+			"Invalid pgaudit.log_level",
+			fmt.Sprintf("Invalid level %q, allowed values: debug1-debug5, info, notice, warning, log.", config.LogLevel.ValueString()),
+		)
+	}
+}
+
+func (r *pgauditSettingResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan pgauditSettingResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	conn, err := r.client.postgreSQLDatabase(ctx, plan.Instance, "")
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+
+	// settings made outside of terraform are reset so that the scope only
+	// has the configured ones
+	current, err := readPgauditSettings(ctx, conn, plan.Role.ValueString(), plan.Database.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read pgaudit settings",
+			err.Error(),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(r.apply(ctx, conn, plan, current)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = types.StringValue(plan.id())
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *pgauditSettingResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state pgauditSettingResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the settings can't be refreshed until the provider configuration is
+	// known, keep the current state until then
+	if r.client.unknown {
+		return
+	}
+
+	conn, err := r.client.postgreSQLDatabase(ctx, state.Instance, "")
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+
+	settings, err := readPgauditSettings(ctx, conn, state.Role.ValueString(), state.Database.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read pgaudit settings",
+			err.Error(),
+		)
+		return
+	}
+
+	// the settings were reset, or the role or database dropped, outside of
+	// terraform
+	if len(settings) == 0 {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	resp.Diagnostics.Append(state.setSettings(settings)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.ID = types.StringValue(state.id())
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *pgauditSettingResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state pgauditSettingResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	conn, err := r.client.postgreSQLDatabase(ctx, plan.Instance, "")
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(r.apply(ctx, conn, plan, state.settings())...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = types.StringValue(plan.id())
+
+	diags := resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *pgauditSettingResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state pgauditSettingResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	conn, err := r.client.postgreSQLDatabase(ctx, state.Instance, "")
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+
+	// resetting every setting, not only the ones in the state, leaves the
+	// scope without pgaudit settings
+	for _, name := range pgauditParameters {
+		if _, err := conn.ExecContext(ctx, state.alterStatement()+" RESET "+name); err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to reset pgaudit setting",
+				fmt.Sprintf("Could not reset %s: %s", name, err.Error()),
+			)
+			return
+		}
+	}
+}
+
+// apply sets the planned settings and resets the current ones that are no
+// longer configured.
+func (r *pgauditSettingResource) apply(ctx context.Context, conn *sql.DB, plan pgauditSettingResourceModel, current map[string]string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	planned := plan.settings()
+	for _, name := range pgauditParameters {
+		value, set := planned[name]
+		old, exists := current[name]
+
+		var query string
+		switch {
+		case set && (!exists || value != old):
+			query = plan.alterStatement() + " SET " + name + " = " + pq.QuoteLiteral(value)
+		case !set && exists:
+			query = plan.alterStatement() + " RESET " + name
+		default:
+			continue
+		}
+
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			diags.AddError(
+				"Unable to set pgaudit setting",
+				fmt.Sprintf("Could not set %s: %s", name, err.Error()),
+			)
+			return diags
+		}
+	}
+
+	return diags
+}
+
+// alterStatement returns the start of the ALTER statement for the scope.
+func (m pgauditSettingResourceModel) alterStatement() string {
+	return pgauditAlterStatement(m.Role.ValueString(), m.Database.ValueString())
+}
+
+// id returns [<instance>/]<role>/<database>, either the role or the
+// database can be empty.
+func (m pgauditSettingResourceModel) id() string {
+	id := m.Role.ValueString() + "/" + m.Database.ValueString()
+	if !m.Instance.IsNull() {
+		id = m.Instance.ValueString() + "/" + id
+	}
+
+	return id
+}
+
+// settings returns the configured settings keyed by parameter name.
+func (m pgauditSettingResourceModel) settings() map[string]string {
+	settings := make(map[string]string)
+
+	if !m.Log.IsNull() {
+		settings["pgaudit.log"] = m.Log.ValueString()
+	}
+	if !m.LogLevel.IsNull() {
+		settings["pgaudit.log_level"] = m.LogLevel.ValueString()
+	}
+
+	for name, value := range m.boolSettings() {
+		if !value.IsNull() {
+			settings[name] = "off"
+			if value.ValueBool() {
+				settings[name] = "on"
+			}
+		}
+	}
+
+	return settings
+}
+
+// setSettings sets the attributes from the settings read from the instance,
+// missing settings are null.
+func (m *pgauditSettingResourceModel) setSettings(settings map[string]string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	m.Log = types.StringNull()
+	if value, ok := settings["pgaudit.log"]; ok {
+		m.Log = types.StringValue(value)
+	}
+
+	m.LogLevel = types.StringNull()
+	if value, ok := settings["pgaudit.log_level"]; ok {
+		m.LogLevel = types.StringValue(value)
+	}
+
+	for name, attr := range m.boolSettings() {
+		*attr = types.BoolNull()
+
+		value, ok := settings[name]
+		if !ok {
+			continue
+		}
+
+		b, ok := parsePgBool(value)
+		if !ok {
+			diags.AddError(
+				"Invalid pgaudit setting",
+				fmt.Sprintf("The %s setting is %q, which is not a boolean.", name, value),
+			)
+			continue
+		}
+		*attr = types.BoolValue(b)
+	}
+
+	return diags
+}
+
+// boolSettings maps the boolean parameters to their attributes.
+func (m *pgauditSettingResourceModel) boolSettings() map[string]*types.Bool {
+	return map[string]*types.Bool{
+		"pgaudit.log_catalog":        &m.LogCatalog,
+		"pgaudit.log_parameter":      &m.LogParameter,
+		"pgaudit.log_relation":       &m.LogRelation,
+		"pgaudit.log_statement_once": &m.LogStatementOnce,
+	}
+}
+
+func (r *pgauditSettingResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(CloudSqlClientAndConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *sql.DB got %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !client.supportsEngine("postgresql") {
+		resp.Diagnostics.AddError(
+			"Must use postgresql engine for pgaudit types",
+			fmt.Sprintf("Configured engine is %q", client.engine),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// ImportState accepts <role>/<database> or <instance>/<role>/<database>,
+// either the role or the database can be empty.
+func (r *pgauditSettingResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts := strings.Split(req.ID, "/")
+	if len(parts) == 3 {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("instance"), parts[0])...)
+		parts = parts[1:]
+	}
+
+	if len(parts) != 2 || (parts[0] == "" && parts[1] == "") {
+		resp.Diagnostics.AddError(
+			"Invalid import id",
+			fmt.Sprintf("Expected [<instance>/]<role>/<database>, got %q.", req.ID),
+		)
+		return
+	}
+
+	for i, name := range []string{"role", "database"} {
+		if parts[i] != "" {
+			resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(name), parts[i])...)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"testing"
+
+	"terraform-provider-cloudsql-auditlog/internal/cloudsqlfake"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccPgauditSettingResource(t *testing.T) {
+	server, providerConfig := newTestPostgresInstance(t)
+	scope := cloudsqlfake.SettingScope{Role: "app_user", Database: "app"}
+
+	// set outside of terraform, reset when the resource is created
+	server.SetSetting(scope, "pgaudit.log_relation", "on")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckPgauditSettings(server, scope, map[string]string{}),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_pgaudit_setting" "test" {
+  role          = "app_user"
+  database      = "app"
+  log           = "read,write"
+  log_parameter = true
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsql-auditlog_pgaudit_setting.test", "id", "app_user/app"),
+					testAccCheckPgauditSettings(server, scope, map[string]string{
+						"pgaudit.log":           "read,write",
+						"pgaudit.log_parameter": "on",
+					}),
+				),
+			},
+			{
+				ResourceName:      "cloudsql-auditlog_pgaudit_setting.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				// drift made outside of terraform is reverted
+				PreConfig: func() {
+					server.SetSetting(scope, "pgaudit.log", "all")
+				},
+				Config: providerConfig + `
+resource "cloudsql-auditlog_pgaudit_setting" "test" {
+  role      = "app_user"
+  database  = "app"
+  log       = "read,write"
+  log_level = "notice"
+}
+`,
+				Check: testAccCheckPgauditSettings(server, scope, map[string]string{
+					"pgaudit.log":       "read,write",
+					"pgaudit.log_level": "notice",
+				}),
+			},
+		},
+	})
+}
+
+func TestAccPgauditSettingResourceDatabase(t *testing.T) {
+	server, providerConfig := newTestPostgresInstance(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_pgaudit_setting" "test" {
+  database    = "app"
+  log         = "ddl"
+  log_catalog = false
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsql-auditlog_pgaudit_setting.test", "id", "/app"),
+					testAccCheckPgauditSettings(server, cloudsqlfake.SettingScope{Database: "app"}, map[string]string{
+						"pgaudit.log":         "ddl",
+						"pgaudit.log_catalog": "off",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPgauditSettingResourceInvalid(t *testing.T) {
+	_, providerConfig := newTestPostgresInstance(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_pgaudit_setting" "test" {
+  log = "read"
+}
+`,
+				ExpectError: regexp.MustCompile("At least one of role and database must be set"),
+			},
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_pgaudit_setting" "test" {
+  role = "app_user"
+  log  = "read,selects"
+}
+`,
+				ExpectError: regexp.MustCompile("Unknown classes selects"),
+			},
+		},
+	})
+}
+
+// testAccCheckPgauditSettings checks every setting stored for a scope.
+func testAccCheckPgauditSettings(server *cloudsqlfake.PostgresServer, scope cloudsqlfake.SettingScope, want map[string]string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		if got := server.Settings(scope); !maps.Equal(got, want) {
+			return fmt.Errorf("expected settings %v for %+v, got %v", want, scope, got)
+		}
+
+		return nil
+	}
+}
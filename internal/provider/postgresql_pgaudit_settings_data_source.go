@@ -0,0 +1,416 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lib/pq"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &pgauditSettingsDataSource{}
+	_ datasource.DataSourceWithConfigure = &pgauditSettingsDataSource{}
+)
+
+// NewPgauditSettingsDataSource is a helper function to simplify the provider implementation.
+func NewPgauditSettingsDataSource() datasource.DataSource {
+	return &pgauditSettingsDataSource{}
+}
+
+// pgauditSettingsDataSource lists the pgaudit configuration of an instance,
+// the postgresql counterpart of auditLogRulesDataSource.
+type pgauditSettingsDataSource struct {
+	client CloudSqlClientAndConfig
+}
+
+// pgauditSettingsDataSourceModel maps the data source schema data.
+type pgauditSettingsDataSourceModel struct {
+	Instance      types.String                 `tfsdk:"instance"`
+	Database      types.String                 `tfsdk:"database"`
+	Role          types.String                 `tfsdk:"role"`
+	Defaults      map[string]types.String      `tfsdk:"defaults"`
+	Settings      []pgauditRoleSettingModel    `tfsdk:"settings"`
+	ObjectAudits  []pgauditObjectGrantModel    `tfsdk:"object_audits"`
+	AuditLogRules []pgauditNormalizedRuleModel `tfsdk:"audit_log_rules"`
+}
+
+// pgauditRoleSettingModel maps a pgaudit entry of pg_db_role_setting.
+type pgauditRoleSettingModel struct {
+	Role     types.String `tfsdk:"role"`
+	Database types.String `tfsdk:"database"`
+	Name     types.String `tfsdk:"name"`
+	Value    types.String `tfsdk:"value"`
+}
+
+// pgauditObjectGrantModel maps a privilege of the auditor role, column is
+// null for privileges on the whole table.
+type pgauditObjectGrantModel struct {
+	Schema    types.String `tfsdk:"schema"`
+	Table     types.String `tfsdk:"table"`
+	Column    types.String `tfsdk:"column"`
+	Privilege types.String `tfsdk:"privilege"`
+}
+
+// pgauditNormalizedRuleModel has the fields of the mysql audit rules, so
+// that the configuration of both engines can be compared.
+type pgauditNormalizedRuleModel struct {
+	Source    types.String `tfsdk:"source"`
+	Username  types.String `tfsdk:"username"`
+	DbName    types.String `tfsdk:"dbname"`
+	Object    types.String `tfsdk:"object"`
+	Operation types.String `tfsdk:"operation"`
+	OpResult  types.String `tfsdk:"op_result"`
+}
+
+// Metadata returns the data source type name.
+func (d *pgauditSettingsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_pgaudit_settings"
+}
+
+// Schema defines the schema for the data source.
+func (d *pgauditSettingsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"instance": schema.StringAttribute{
+				Optional: true,
+			},
+			"database": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+			},
+			"role": schema.StringAttribute{
+				Computed: true,
+			},
+			"defaults": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+			"settings": schema.ListNestedAttribute{
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"role": schema.StringAttribute{
+							Computed: true,
+						},
+						"database": schema.StringAttribute{
+							Computed: true,
+						},
+						"name": schema.StringAttribute{
+							Computed: true,
+						},
+						"value": schema.StringAttribute{
+							Computed: true,
+						},
+					},
+				},
+			},
+			"object_audits": schema.ListNestedAttribute{
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"schema": schema.StringAttribute{
+							Computed: true,
+						},
+						"table": schema.StringAttribute{
+							Computed: true,
+						},
+						"column": schema.StringAttribute{
+							Computed: true,
+						},
+						"privilege": schema.StringAttribute{
+							Computed: true,
+						},
+					},
+				},
+			},
+			"audit_log_rules": schema.ListNestedAttribute{
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"source": schema.StringAttribute{
+							Computed: true,
+						},
+						"username": schema.StringAttribute{
+							Computed: true,
+						},
+						"dbname": schema.StringAttribute{
+							Computed: true,
+						},
+						"object": schema.StringAttribute{
+							Computed: true,
+						},
+						"operation": schema.StringAttribute{
+							Computed: true,
+						},
+						"op_result": schema.StringAttribute{
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *pgauditSettingsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state pgauditSettingsDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if d.client.unknown {
+		deferDataSourceRead(req, resp)
+		return
+	}
+
+	conn, err := d.client.postgreSQLDatabase(ctx, state.Instance, state.Database.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to connect to instance",
+			err.Error(),
+		)
+		return
+	}
+
+	var database string
+	if err := conn.QueryRowContext(ctx, readCurrentDatabase).Scan(&database); err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to query current database",
+			err.Error(),
+		)
+		return
+	}
+	state.Database = types.StringValue(database)
+
+	// the defaults are the values in effect for the provider connection,
+	// i.e., the database flags unless they are set for the database or role
+	state.Defaults = make(map[string]types.String)
+	for _, name := range slices.Concat(pgauditParameters, []string{"pgaudit.role"}) {
+		var value sql.NullString
+		if err := conn.QueryRowContext(ctx, readCurrentSetting, name).Scan(&value); err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to query pgaudit settings",
+				err.Error(),
+			)
+			return
+		}
+
+		if value.Valid {
+			state.Defaults[name] = types.StringValue(value.String)
+		}
+	}
+
+	state.AuditLogRules = []pgauditNormalizedRuleModel{}
+	if log := state.Defaults["pgaudit.log"].ValueString(); auditsStatements(log) {
+		state.AuditLogRules = append(state.AuditLogRules, normalizedRule("default", "", "", "*", log))
+	}
+
+	state.Settings, err = readAllPgauditSettings(ctx, conn)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to query pgaudit settings",
+			err.Error(),
+		)
+		return
+	}
+
+	for _, setting := range state.Settings {
+		if setting.Name.ValueString() == "pgaudit.log" && auditsStatements(setting.Value.ValueString()) {
+			state.AuditLogRules = append(state.AuditLogRules, normalizedRule("setting",
+				setting.Role.ValueString(), setting.Database.ValueString(), "*", setting.Value.ValueString()))
+		}
+	}
+
+	// object audit logging is off until pgaudit.role is set
+	state.Role = types.StringNull()
+	state.ObjectAudits = []pgauditObjectGrantModel{}
+	if role := state.Defaults["pgaudit.role"].ValueString(); role != "" {
+		state.Role = types.StringValue(role)
+
+		state.ObjectAudits, err = readAllObjectGrants(ctx, conn, role)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to query object audit grants",
+				err.Error(),
+			)
+			return
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, grant := range state.ObjectAudits {
+		object := grant.Schema.ValueString() + "." + grant.Table.ValueString()
+		operation := strings.ToLower(grant.Privilege.ValueString())
+		if key := object + " " + operation; !seen[key] {
+			seen[key] = true
+			state.AuditLogRules = append(state.AuditLogRules, normalizedRule("object", "", database, object, operation))
+		}
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// readAllPgauditSettings returns the pgaudit entries of pg_db_role_setting,
+// including pgaudit.role, an empty role or database applies to all of them.
+func readAllPgauditSettings(ctx context.Context, conn *sql.DB) ([]pgauditRoleSettingModel, error) {
+	rows, err := conn.QueryContext(ctx, readAllPgauditRoleSettings)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := []pgauditRoleSettingModel{}
+	for rows.Next() {
+		var role, database string
+		var config []string
+		if err := rows.Scan(&role, &database, pq.Array(&config)); err != nil {
+			return nil, err
+		}
+
+		values := pgauditSettingsFromConfig(config)
+		for _, name := range slices.Sorted(maps.Keys(values)) {
+			settings = append(settings, pgauditRoleSettingModel{
+				Role:     types.StringValue(role),
+				Database: types.StringValue(database),
+				Name:     types.StringValue(name),
+				Value:    types.StringValue(values[name]),
+			})
+		}
+	}
+
+	return settings, rows.Err()
+}
+
+// readAllObjectGrants returns the privileges of the auditor role on every
+// table of the database, the column privileges that only repeat a table
+// privilege are left out.
+func readAllObjectGrants(ctx context.Context, conn *sql.DB, role string) ([]pgauditObjectGrantModel, error) {
+	grants := []pgauditObjectGrantModel{}
+	tableLevel := make(map[[3]string]bool)
+
+	rows, err := conn.QueryContext(ctx, readAllRoleTableGrants, role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schema, table, privilege string
+		if err := rows.Scan(&schema, &table, &privilege); err != nil {
+			return nil, err
+		}
+
+		if pgauditObjectPrivileges[privilege] {
+			tableLevel[[3]string{schema, table, privilege}] = true
+			grants = append(grants, pgauditObjectGrantModel{
+				Schema:    types.StringValue(schema),
+				Table:     types.StringValue(table),
+				Column:    types.StringNull(),
+				Privilege: types.StringValue(privilege),
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	columnRows, err := conn.QueryContext(ctx, readAllRoleColumnGrants, role)
+	if err != nil {
+		return nil, err
+	}
+	defer columnRows.Close()
+
+	for columnRows.Next() {
+		var schema, table, column, privilege string
+		if err := columnRows.Scan(&schema, &table, &column, &privilege); err != nil {
+			return nil, err
+		}
+
+		if pgauditObjectPrivileges[privilege] && !tableLevel[[3]string{schema, table, privilege}] {
+			grants = append(grants, pgauditObjectGrantModel{
+				Schema:    types.StringValue(schema),
+				Table:     types.StringValue(table),
+				Column:    types.StringValue(column),
+				Privilege: types.StringValue(privilege),
+			})
+		}
+	}
+
+	return grants, columnRows.Err()
+}
+
+// auditsStatements reports whether a pgaudit.log value logs any statement
+// class.
+func auditsStatements(log string) bool {
+	log = strings.TrimSpace(strings.ToLower(log))
+	return log != "" && log != "none"
+}
+
+// normalizedRule returns a rule in the mysql model, an empty role or
+// database matches everything like "*" does there. pgaudit only logs the
+// statements that are executed, which are the successful ones.
+func normalizedRule(source, role, database, object, operation string) pgauditNormalizedRuleModel {
+	if role == "" {
+		role = "*"
+	}
+	if database == "" {
+		database = "*"
+	}
+
+	return pgauditNormalizedRuleModel{
+		Source:    types.StringValue(source),
+		Username:  types.StringValue(role),
+		DbName:    types.StringValue(database),
+		Object:    types.StringValue(object),
+		Operation: types.StringValue(operation),
+		OpResult:  types.StringValue("S"),
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *pgauditSettingsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(CloudSqlClientAndConfig)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *sql.DB got %T.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !client.supportsEngine("postgresql") {
+		resp.Diagnostics.AddError(
+			"Must use postgresql engine for pgaudit types",
+			fmt.Sprintf("Configured engine is %q", client.engine),
+		)
+
+		return
+	}
+
+	d.client = client
+}
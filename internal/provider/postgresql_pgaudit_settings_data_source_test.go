@@ -0,0 +1,49 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-cloudsql-auditlog/internal/cloudsqlfake"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccPgauditSettingsDataSource(t *testing.T) {
+	server, providerConfig := newTestPostgresInstance(t)
+	server.Flags["pgaudit.log"] = "ddl"
+	server.SetSetting(cloudsqlfake.SettingScope{Database: "app"}, "pgaudit.role", "auditor")
+	server.SetSetting(cloudsqlfake.SettingScope{Role: "app_user", Database: "app"}, "pgaudit.log", "read,write")
+	server.SetSetting(cloudsqlfake.SettingScope{Role: "app_user", Database: "app"}, "pgaudit.log_parameter", "on")
+	server.AddGrant(cloudsqlfake.Grant{Database: "app", Schema: "public", Table: "orders", Role: "auditor", Privilege: "DELETE"})
+	server.AddGrant(cloudsqlfake.Grant{Database: "app", Schema: "public", Table: "orders", Role: "auditor", Privilege: "SELECT", Column: "total"})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `data "cloudsql-auditlog_pgaudit_settings" "test" { database = "app" }`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_pgaudit_settings.test", "role", "auditor"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_pgaudit_settings.test", "defaults.pgaudit.log", "ddl"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_pgaudit_settings.test", "settings.#", "3"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_pgaudit_settings.test", "settings.0.role", ""),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_pgaudit_settings.test", "settings.0.name", "pgaudit.role"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_pgaudit_settings.test", "settings.1.value", "read,write"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_pgaudit_settings.test", "object_audits.#", "2"),
+					resource.TestCheckNoResourceAttr("data.cloudsql-auditlog_pgaudit_settings.test", "object_audits.0.column"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_pgaudit_settings.test", "object_audits.1.column", "total"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_pgaudit_settings.test", "audit_log_rules.#", "4"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_pgaudit_settings.test", "audit_log_rules.0.source", "default"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_pgaudit_settings.test", "audit_log_rules.1.username", "app_user"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_pgaudit_settings.test", "audit_log_rules.1.dbname", "app"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_pgaudit_settings.test", "audit_log_rules.2.object", "public.orders"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_pgaudit_settings.test", "audit_log_rules.2.operation", "delete"),
+					resource.TestCheckResourceAttr("data.cloudsql-auditlog_pgaudit_settings.test", "audit_log_rules.3.operation", "select"),
+				),
+			},
+		},
+	})
+}
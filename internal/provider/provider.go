@@ -5,25 +5,26 @@ package provider
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
 
-	"github.com/go-sql-driver/mysql"
-
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/list"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure ScaffoldingProvider satisfies various provider interfaces.
 var _ provider.Provider = &ScaffoldingProvider{}
 var _ provider.ProviderWithFunctions = &ScaffoldingProvider{}
 var _ provider.ProviderWithEphemeralResources = &ScaffoldingProvider{}
+var _ provider.ProviderWithListResources = &ScaffoldingProvider{}
 
 // ScaffoldingProvider defines the provider implementation.
 type ScaffoldingProvider struct {
@@ -40,11 +41,61 @@ type cloudsqlAuditlogProviderModel struct {
 	Password types.String `tfsdk:"password"`
 	Engine   types.String `tfsdk:"engine"`
 	Tls      types.String `tfsdk:"tls"`
+
+	PasswordCommand        types.List   `tfsdk:"password_command"`
+	PasswordCommandTimeout types.String `tfsdk:"password_command_timeout"`
+	PasswordFile           types.String `tfsdk:"password_file"`
+	IamAuth                types.Bool   `tfsdk:"iam_auth"`
+
+	Instances types.Map `tfsdk:"instances"`
+
+	Guardrails types.Object `tfsdk:"guardrails"`
+}
+
+func (m cloudsqlAuditlogProviderModel) connectionAttributes() connectionAttributes {
+	return connectionAttributes{
+		Endpoint:               m.Endpoint,
+		Username:               m.Username,
+		Password:               m.Password,
+		PasswordCommand:        m.PasswordCommand,
+		PasswordCommandTimeout: m.PasswordCommandTimeout,
+		PasswordFile:           m.PasswordFile,
+		Tls:                    m.Tls,
+		IamAuth:                m.IamAuth,
+	}
 }
 
 type CloudSqlClientAndConfig struct {
-	client *sql.DB
-	engine string
+	engine      string
+	connections *instanceConnections
+
+	// unknown is set when the provider was configured with values that are
+	// only known during apply, the connections aren't available until then
+	unknown bool
+
+	// guardrails restrict the audit rules that can be planned, nil when
+	// the provider doesn't configure any
+	guardrails *auditGuardrails
+}
+
+// connection returns the connection pool to the given instance, a null or
+// empty instance selects the connection configured at the top-level.
+func (c CloudSqlClientAndConfig) connection(ctx context.Context, instance types.String) (*instanceConnection, error) {
+	if c.unknown {
+		return nil, errors.New("the provider configuration is not known yet")
+	}
+
+	if c.connections == nil {
+		return nil, errors.New("provider is not configured")
+	}
+
+	return c.connections.get(ctx, instance.ValueString())
+}
+
+// supportsEngine reports whether the provider is configured for the engine,
+// an unknown engine is accepted until the configuration is known.
+func (c CloudSqlClientAndConfig) supportsEngine(engine string) bool {
+	return c.engine == engine || (c.unknown && c.engine == "")
 }
 
 func (p *ScaffoldingProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -56,12 +107,12 @@ func (p *ScaffoldingProvider) Schema(ctx context.Context, req provider.SchemaReq
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"endpoint": schema.StringAttribute{
-				Required: true,
-				Optional: false,
+				Required: false,
+				Optional: true, // not needed when only using instances
 			},
 			"username": schema.StringAttribute{
-				Required: true,
-				Optional: false,
+				Required: false,
+				Optional: true,
 			},
 			"password": schema.StringAttribute{
 				Required:  false,
@@ -76,6 +127,84 @@ func (p *ScaffoldingProvider) Schema(ctx context.Context, req provider.SchemaReq
 				Required: false,
 				Optional: true,
 			},
+			"password_command": schema.ListAttribute{
+				Required:    false,
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"password_command_timeout": schema.StringAttribute{
+				Required: false,
+				Optional: true,
+			},
+			"password_file": schema.StringAttribute{
+				Required: false,
+				Optional: true,
+			},
+			"iam_auth": schema.BoolAttribute{
+				Required: false,
+				Optional: true,
+			},
+			"instances": schema.MapNestedAttribute{
+				Required: false,
+				Optional: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"endpoint": schema.StringAttribute{
+							Required: true,
+						},
+						"username": schema.StringAttribute{
+							Required: true,
+						},
+						"password": schema.StringAttribute{
+							Optional:  true,
+							Sensitive: true,
+						},
+						"password_command": schema.ListAttribute{
+							Optional:    true,
+							ElementType: types.StringType,
+						},
+						"password_command_timeout": schema.StringAttribute{
+							Optional: true,
+						},
+						"password_file": schema.StringAttribute{
+							Optional: true,
+						},
+						"tls": schema.StringAttribute{
+							Optional: true,
+						},
+						"iam_auth": schema.BoolAttribute{
+							Optional: true,
+						},
+					},
+				},
+			},
+			"guardrails": schema.SingleNestedAttribute{
+				Optional: true,
+				Attributes: map[string]schema.Attribute{
+					"max_wildcards": schema.Int64Attribute{
+						Optional: true,
+					},
+					"disallowed_operations": schema.SetAttribute{
+						Optional:    true,
+						ElementType: types.StringType,
+					},
+					"broad_rule_wildcards": schema.Int64Attribute{
+						Optional: true,
+					},
+					"broad_rule_op_results": schema.SetAttribute{
+						Optional:    true,
+						ElementType: types.StringType,
+					},
+					"allowed_users": schema.ListAttribute{
+						Optional:    true,
+						ElementType: types.StringType,
+					},
+					"denied_users": schema.ListAttribute{
+						Optional:    true,
+						ElementType: types.StringType,
+					},
+				},
+			},
 		},
 	}
 }
@@ -91,85 +220,70 @@ func (p *ScaffoldingProvider) Configure(ctx context.Context, req provider.Config
 
 	// Configuration values are now available.
 
-	if data.Endpoint.IsUnknown() {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("endpoint"),
-			"Unknown endpoint",
-			"Must set mysql endpoint",
-		)
+	instances := make(map[string]connectionAttributes)
+	if !data.Instances.IsNull() && !data.Instances.IsUnknown() {
+		resp.Diagnostics.Append(data.Instances.ElementsAs(ctx, &instances, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
 	}
 
-	if data.Username.IsUnknown() {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("username"),
-			"Unknown username",
-			"Must set mysql username",
-		)
+	guardrails, diags := guardrailsFromAttributes(ctx, data.Guardrails)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	if data.Password.IsUnknown() {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("password"),
-			"Unknown password",
-			"Must set mysql password",
-		)
+	unknown := data.Engine.IsUnknown() || data.Instances.IsUnknown() || data.connectionAttributes().isUnknown()
+	for _, instance := range instances {
+		unknown = unknown || instance.isUnknown()
 	}
 
-	if data.Engine.IsUnknown() {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("engine"),
-			"Unknown engine",
-			"Must set engine type",
-		)
-	}
+	// the connection details aren't known when the instance is created in
+	// the same apply, in which case the connections are only opened once
+	// terraform configures the provider again with the final values
+	if unknown {
+		if req.ClientCapabilities.DeferralAllowed {
+			resp.Deferred = &provider.Deferred{
+				Reason: provider.DeferredReasonProviderConfigUnknown,
+			}
+			return
+		}
 
-	if data.Tls.IsUnknown() {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("tls"),
-			"Unknown tls",
-			"Must set tls option",
-		)
-	}
+		clientEngine := CloudSqlClientAndConfig{
+			engine:     data.Engine.ValueString(),
+			unknown:    true,
+			guardrails: guardrails,
+		}
 
-	if resp.Diagnostics.HasError() {
+		resp.DataSourceData = clientEngine
+		resp.ResourceData = clientEngine
+		resp.ListResourceData = clientEngine
 		return
 	}
 
-	endpoint := ""
-	username := ""
-	password := ""
-	tls := "false"
-
-	if !data.Endpoint.IsNull() {
-		endpoint = data.Endpoint.ValueString()
-	}
-
-	if !data.Username.IsNull() {
-		username = data.Username.ValueString()
-	}
-
-	if !data.Password.IsNull() {
-		password = data.Password.ValueString()
-	}
+	configs := make(map[string]connectionConfig)
 
-	if !data.Tls.IsNull() {
-		tls = data.Tls.ValueString()
+	// the top-level connection is optional when instances are configured
+	if !data.Endpoint.IsNull() || !data.Username.IsNull() || len(instances) == 0 {
+		cfg, diags := connectionConfigFromAttributes(ctx, path.Empty(), data.connectionAttributes())
+		resp.Diagnostics.Append(diags...)
+		configs[defaultInstance] = cfg
 	}
 
-	if endpoint == "" {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("endpoint"),
-			"Missing mysql endpoint",
-			"Must set mysql endpoint",
-		)
-	}
+	for name, instance := range instances {
+		if name == defaultInstance {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("instances"),
+				"Invalid instance name",
+				"Instance names must not be empty",
+			)
+			continue
+		}
 
-	if username == "" {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("username"),
-			"Missing mysql username",
-			"Must set mysql username",
-		)
+		cfg, diags := connectionConfigFromAttributes(ctx, path.Root("instances").AtMapKey(name), instance)
+		resp.Diagnostics.Append(diags...)
+		configs[name] = cfg
 	}
 
 	if data.Engine.ValueString() != "mysql" && data.Engine.ValueString() != "postgresql" {
@@ -184,60 +298,84 @@ func (p *ScaffoldingProvider) Configure(ctx context.Context, req provider.Config
 		return
 	}
 
-	if data.Engine.ValueString() == "mysql" {
-		cfg := mysql.NewConfig()
-
-		cfg.User = username
-		cfg.Passwd = password
-		cfg.Net = "tcp"
-		cfg.Addr = endpoint
-		cfg.DBName = "mysql"
-		cfg.TLSConfig = tls
+	for name, cfg := range configs {
+		cfg.engine = data.Engine.ValueString()
+		configs[name] = cfg
+	}
 
-		conn, err := mysql.NewConnector(cfg)
-		if err != nil {
+	// the top-level password is resolved right away so that errors are
+	// reported on configure, the instance passwords when they're first used
+	if cfg, ok := configs[defaultInstance]; ok {
+		if err := cfg.resolvePassword(ctx); err != nil {
 			resp.Diagnostics.AddError(
-				fmt.Sprintf("unable to parse connection options: %v", err),
-				"Unable to call mysql new connector",
+				"Unable to resolve password",
+				err.Error(),
 			)
 			return
 		}
+		configs[defaultInstance] = cfg
+	}
 
-		db := sql.OpenDB(conn)
-		clientEngine := CloudSqlClientAndConfig{
-			client: db,
-			engine: data.Engine.ValueString(),
-		}
-
-		resp.DataSourceData = clientEngine
-		resp.ResourceData = clientEngine
-	} else {
-		resp.Diagnostics.AddError(
-			"TODO",
-			"postgresql not implemented yet",
-		)
-		return
+	clientEngine := CloudSqlClientAndConfig{
+		engine:      data.Engine.ValueString(),
+		connections: newInstanceConnections(configs),
+		guardrails:  guardrails,
 	}
+
+	_, hasDefault := configs[defaultInstance]
+	tflog.Debug(ctx, "Configured cloudsql-auditlog provider", map[string]interface{}{
+		"engine":             clientEngine.engine,
+		"default_connection": hasDefault,
+		"instances":          clientEngine.connections.names(),
+		"guardrails":         guardrails != nil,
+	})
+
+	resp.DataSourceData = clientEngine
+	resp.ResourceData = clientEngine
+	resp.ListResourceData = clientEngine
 }
 
 func (p *ScaffoldingProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewAuditLogRuleResource,
+		NewPgauditExtensionResource,
+		NewPgauditSettingResource,
+		NewPgauditObjectAuditResource,
+		NewAuditPolicyResource,
+		NewAuditLogBaselineResource,
 	}
 }
 
 func (p *ScaffoldingProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
-	return []func() ephemeral.EphemeralResource{}
+	return []func() ephemeral.EphemeralResource{
+		NewIAMTokenEphemeralResource,
+	}
+}
+
+func (p *ScaffoldingProvider) ListResources(ctx context.Context) []func() list.ListResource {
+	return []func() list.ListResource{
+		NewAuditLogRuleListResource,
+	}
 }
 
 func (p *ScaffoldingProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewAuditLogRulesDataSource,
+		NewAuditLogPluginDataSource,
+		NewMySQLUsersDataSource,
+		NewStaleAuditRulesDataSource,
+		NewPgauditSettingsDataSource,
+		NewAuditLogVolumeEstimateDataSource,
 	}
 }
 
 func (p *ScaffoldingProvider) Functions(ctx context.Context) []func() function.Function {
-	return []func() function.Function{}
+	return []func() function.Function{
+		NewNormalizeAccountFunction,
+		NewParseAccountFunction,
+		NewRuleMatchesFunction,
+		NewExpandOperationsFunction,
+	}
 }
 
 func New(version string) func() provider.Provider {
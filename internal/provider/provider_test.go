@@ -0,0 +1,232 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"terraform-provider-cloudsql-auditlog/internal/cloudsqlfake"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// testAccProtoV6ProviderFactories are used to instantiate a provider during
+// acceptance testing. The factory function will be invoked for every Terraform
+// CLI command executed to create a provider server to which the CLI can
+// reattach.
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"cloudsql-auditlog": providerserver.NewProtocol6WithError(New("test")()),
+}
+
+// testInstances maps the endpoints used in the test configurations to the
+// fake instances.
+var testInstances sync.Map
+
+func init() {
+	openConnection = func(cfg connectionConfig) (*sql.DB, error) {
+		if server, ok := testInstances.Load(cfg.endpoint); ok {
+			switch server := server.(type) {
+			case *cloudsqlfake.Server:
+				return server.DB(), nil
+			case *cloudsqlfake.PostgresServer:
+				database := cfg.database
+				if database == "" {
+					database = defaultPostgreSQLDatabase
+				}
+				return server.DB(database), nil
+			}
+		}
+
+		return openDatabase(cfg)
+	}
+}
+
+// newTestInstance starts a fake Cloud SQL instance for the test and returns
+// it with the provider configuration connecting to it.
+func newTestInstance(t *testing.T) (*cloudsqlfake.Server, string) {
+	t.Helper()
+
+	server := cloudsqlfake.New()
+	endpoint := "fake-" + strings.ReplaceAll(t.Name(), "/", "-")
+
+	testInstances.Store(endpoint, server)
+	t.Cleanup(func() { testInstances.Delete(endpoint) })
+
+	config := fmt.Sprintf(`
+provider "cloudsql-auditlog" {
+  engine   = "mysql"
+  endpoint = %q
+  username = "root"
+  password = "secret"
+}
+`, endpoint)
+
+	return server, config
+}
+
+// newTestPostgresInstance is newTestInstance for a fake Cloud SQL for
+// PostgreSQL instance.
+func newTestPostgresInstance(t *testing.T) (*cloudsqlfake.PostgresServer, string) {
+	t.Helper()
+
+	server := cloudsqlfake.NewPostgres()
+	endpoint := "fake-" + strings.ReplaceAll(t.Name(), "/", "-")
+
+	testInstances.Store(endpoint, server)
+	t.Cleanup(func() { testInstances.Delete(endpoint) })
+
+	config := fmt.Sprintf(`
+provider "cloudsql-auditlog" {
+  engine   = "postgresql"
+  endpoint = %q
+  username = "postgres"
+  password = "secret"
+}
+`, endpoint)
+
+	return server, config
+}
+
+func TestAccProviderInstances(t *testing.T) {
+	primary, _ := newTestInstance(t)
+	replica := cloudsqlfake.New()
+	testInstances.Store("fake-"+t.Name()+"-replica", replica)
+	t.Cleanup(func() { testInstances.Delete("fake-" + t.Name() + "-replica") })
+
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(passwordFile, []byte("secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	providerConfig := fmt.Sprintf(`
+provider "cloudsql-auditlog" {
+  engine = "mysql"
+
+  instances = {
+    primary = {
+      endpoint = "fake-%[1]s"
+      username = "root"
+      password = "secret"
+    }
+    replica = {
+      endpoint      = "fake-%[1]s-replica"
+      username      = "root"
+      password_file = %[2]q
+    }
+  }
+}
+`, t.Name(), passwordFile)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "cloudsql-auditlog_audit_log_rule" "test" {
+  instance  = "replica"
+  username  = "*"
+  dbname    = "*"
+  object    = "*"
+  operation = "ddl"
+  op_result = "B"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudsql-auditlog_audit_log_rule.test", "id", "1"),
+					testAccCheckRuleCount(replica, 1),
+					testAccCheckRuleCount(primary, 0),
+				),
+			},
+			{
+				Config:      providerConfig + `data "cloudsql-auditlog_audit_log_rules" "test" {}`,
+				ExpectError: regexp.MustCompile(`no default connection is configured, set instance to one of: primary,\s+replica`),
+			},
+			{
+				Config: providerConfig + `
+data "cloudsql-auditlog_audit_log_rules" "test" {
+  instance = "standby"
+}
+`,
+				ExpectError: regexp.MustCompile(`instance "standby" is not configured`),
+			},
+		},
+	})
+}
+
+func TestAccProviderPasswordSources(t *testing.T) {
+	server, _ := newTestInstance(t)
+	server.AddRule(cloudsqlfake.Rule{
+		Username:  "*",
+		Dbname:    "*",
+		Object:    "*",
+		Operation: "ddl",
+		OpResult:  "B",
+	})
+
+	endpoint := "fake-" + t.Name()
+	providerConfig := func(password string) string {
+		return fmt.Sprintf(`
+provider "cloudsql-auditlog" {
+  engine   = "mysql"
+  endpoint = %q
+  username = "root"
+  %s
+}
+
+data "cloudsql-auditlog_audit_log_rules" "test" {}
+`, endpoint, password)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      providerConfig(`password_command = ["sh", "-c", "echo secret; exit 1"]`),
+				ExpectError: regexp.MustCompile(`Unable to resolve password`),
+			},
+			{
+				Config: providerConfig(`
+  password_command         = ["sleep", "5"]
+  password_command_timeout = "100ms"
+`),
+				ExpectError: regexp.MustCompile(`sleep timed out after 100ms`),
+			},
+			{
+				Config: providerConfig(`
+  password_command         = ["echo", "secret"]
+  password_command_timeout = "0s"
+`),
+				ExpectError: regexp.MustCompile(`Invalid password command timeout`),
+			},
+			{
+				Config:      providerConfig(`password_file = "/nonexistent/password"`),
+				ExpectError: regexp.MustCompile(`unable to read password file`),
+			},
+			{
+				Config: providerConfig(`
+  password      = "secret"
+  password_file = "/run/secrets/password"
+`),
+				ExpectError: regexp.MustCompile(`Conflicting password options`),
+			},
+			{
+				Config: providerConfig(`password = "secret"`),
+				Check:  resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_rules.test", "audit_log_rules.#", "1"),
+			},
+			{
+				Config: providerConfig(`password_command = ["echo", "secret"]`),
+				Check:  resource.TestCheckResourceAttr("data.cloudsql-auditlog_audit_log_rules.test", "audit_log_rules.#", "1"),
+			},
+		},
+	})
+}
@@ -0,0 +1,112 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &ruleMatchesFunction{}
+
+func NewRuleMatchesFunction() function.Function {
+	return &ruleMatchesFunction{}
+}
+
+type ruleMatchesFunction struct{}
+
+// ruleMatchesRuleModel maps the rule argument, any other attributes (e.g.,
+// the id of the audit_log_rules data source entries) are ignored.
+type ruleMatchesRuleModel struct {
+	Username  types.String `tfsdk:"username"`
+	DbName    types.String `tfsdk:"dbname"`
+	Object    types.String `tfsdk:"object"`
+	Operation types.String `tfsdk:"operation"`
+	OpResult  types.String `tfsdk:"op_result"`
+}
+
+// ruleMatchesEventModel maps the event argument.
+type ruleMatchesEventModel struct {
+	User      types.String `tfsdk:"user"`
+	Host      types.String `tfsdk:"host"`
+	Db        types.String `tfsdk:"db"`
+	Object    types.String `tfsdk:"object"`
+	Operation types.String `tfsdk:"operation"`
+	Result    types.String `tfsdk:"result"`
+}
+
+func (f *ruleMatchesFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "rule_matches"
+}
+
+func (f *ruleMatchesFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Check whether an audit rule matches an event",
+		Description: "Returns true when the audit rule covers the event, using the same matching as the rest of the provider. Exclusion rules (op_result E) match the events they exclude.",
+		Parameters: []function.Parameter{
+			function.ObjectParameter{
+				Name:        "rule",
+				Description: "Audit rule with the username, dbname, object, operation and op_result attributes.",
+				AttributeTypes: map[string]attr.Type{
+					"username":  types.StringType,
+					"dbname":    types.StringType,
+					"object":    types.StringType,
+					"operation": types.StringType,
+					"op_result": types.StringType,
+				},
+			},
+			function.ObjectParameter{
+				Name:        "event",
+				Description: "Event with the user, host (the client host), db, object, operation and result (S or U) attributes.",
+				AttributeTypes: map[string]attr.Type{
+					"user":      types.StringType,
+					"host":      types.StringType,
+					"db":        types.StringType,
+					"object":    types.StringType,
+					"operation": types.StringType,
+					"result":    types.StringType,
+				},
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *ruleMatchesFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var rule ruleMatchesRuleModel
+	var event ruleMatchesEventModel
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &rule, &event))
+	if resp.Error != nil {
+		return
+	}
+
+	result := strings.ToUpper(event.Result.ValueString())
+	if result != "S" && result != "U" {
+		resp.Error = function.NewArgumentFuncError(1, `event result must be either "S" or "U"`)
+		return
+	}
+
+	matches := ruleMatchesEvent(AuditRule{
+		Username:  rule.Username.ValueString(),
+		Dbname:    rule.DbName.ValueString(),
+		Object:    rule.Object.ValueString(),
+		Operation: rule.Operation.ValueString(),
+		OpResult:  rule.OpResult.ValueString(),
+	}, auditEvent{
+		User:      event.User.ValueString(),
+		Host:      event.Host.ValueString(),
+		Db:        event.Db.ValueString(),
+		Object:    event.Object.ValueString(),
+		Operation: event.Operation.ValueString(),
+		Result:    result,
+	})
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, matches))
+}
@@ -0,0 +1,96 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestRuleMatchesFunction(t *testing.T) {
+	ruleTypes := map[string]attr.Type{
+		"username":  types.StringType,
+		"dbname":    types.StringType,
+		"object":    types.StringType,
+		"operation": types.StringType,
+		"op_result": types.StringType,
+	}
+	eventTypes := map[string]attr.Type{
+		"user":      types.StringType,
+		"host":      types.StringType,
+		"db":        types.StringType,
+		"object":    types.StringType,
+		"operation": types.StringType,
+		"result":    types.StringType,
+	}
+
+	object := func(attributeTypes map[string]attr.Type, values map[string]string) types.Object {
+		attributes := make(map[string]attr.Value)
+		for name := range attributeTypes {
+			attributes[name] = types.StringValue(values[name])
+		}
+		return types.ObjectValueMust(attributeTypes, attributes)
+	}
+
+	event := map[string]string{"user": "app", "host": "192.168.0.5", "db": "shop", "object": "orders", "operation": "insert", "result": "S"}
+
+	tests := []struct {
+		name string
+		rule map[string]string
+		want bool
+	}{
+		{
+			name: "operation class",
+			rule: map[string]string{"username": "app@%", "dbname": "shop", "object": "*", "operation": "dml", "op_result": "B"},
+			want: true,
+		},
+		{
+			name: "client host pattern",
+			rule: map[string]string{"username": "app@192.168.%", "dbname": "*", "object": "*", "operation": "*", "op_result": "B"},
+			want: true,
+		},
+		{
+			name: "other host pattern",
+			rule: map[string]string{"username": "app@10.%", "dbname": "*", "object": "*", "operation": "*", "op_result": "B"},
+		},
+		{
+			name: "wildcard database",
+			rule: map[string]string{"username": "*", "dbname": "sh*", "object": "*", "operation": "insert", "op_result": "S"},
+			want: true,
+		},
+		{
+			name: "unsuccessful only",
+			rule: map[string]string{"username": "*", "dbname": "*", "object": "*", "operation": "*", "op_result": "U"},
+		},
+		{
+			name: "exclusion",
+			rule: map[string]string{"username": "app", "dbname": "*", "object": "orders,customers", "operation": "*", "op_result": "E"},
+			want: true,
+		},
+		{
+			name: "other operation",
+			rule: map[string]string{"username": "*", "dbname": "*", "object": "*", "operation": "ddl", "op_result": "B"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testRunFunction(t, NewRuleMatchesFunction(), types.BoolUnknown(), object(ruleTypes, tt.rule), object(eventTypes, event))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.(types.Bool).ValueBool() != tt.want {
+				t.Errorf("expected %t, got %s", tt.want, got)
+			}
+		})
+	}
+
+	invalid := map[string]string{"user": "app", "host": "%", "result": "X"}
+	if _, err := testRunFunction(t, NewRuleMatchesFunction(), types.BoolUnknown(), object(ruleTypes, tests[0].rule), object(eventTypes, invalid)); err == nil {
+		t.Error("expected an invalid event result to fail")
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"terraform-provider-cloudsql-auditlog/db"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// sqlLogSubsystem is the tflog subsystem used to trace the database calls,
+// it follows the TF_LOG_PROVIDER level like the rest of the provider logs.
+const sqlLogSubsystem = "sql"
+
+// maxLoggedArgLength is the length after which string parameters are
+// truncated in the logs.
+const maxLoggedArgLength = 256
+
+// maskedSecret replaces the secrets in the logged parameters, like tflog
+// does for the top-level fields it masks.
+const maskedSecret = "***"
+
+const readProcedureOutVars = "SELECT @outval, @outmsg"
+
+// sqlcQueryName matches the name comment sqlc puts in front of every query.
+var sqlcQueryName = regexp.MustCompile(`^-- name: (\w+)`)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ db.DBTX = &tracingDBTX{}
+
+// tracingDBTX logs every call made by the sqlc queries. It is meant to wrap a
+// single *sql.Conn so that the @outval and @outmsg session variables set by
+// the cloudsql procedures can be read back after each call.
+type tracingDBTX struct {
+	conn    db.DBTX
+	secrets []string
+}
+
+func (t *tracingDBTX) logContext(ctx context.Context) context.Context {
+	ctx = tflog.NewSubsystem(ctx, sqlLogSubsystem)
+	ctx = tflog.SubsystemMaskFieldValuesWithFieldKeys(ctx, sqlLogSubsystem, "password")
+
+	if len(t.secrets) > 0 {
+		ctx = tflog.SubsystemMaskLogStrings(ctx, sqlLogSubsystem, t.secrets...)
+	}
+
+	return ctx
+}
+
+func (t *tracingDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	logCtx := t.logContext(ctx)
+	fields := queryLogFields(query, args, t.secrets)
+
+	start := time.Now()
+	result, err := t.conn.ExecContext(ctx, query, args...)
+	fields["duration_ms"] = time.Since(start).Milliseconds()
+
+	if err != nil {
+		fields["error"] = err.Error()
+		tflog.SubsystemError(logCtx, sqlLogSubsystem, "SQL exec failed", fields)
+		return result, err
+	}
+
+	if rows, err := result.RowsAffected(); err == nil {
+		fields["rows_affected"] = rows
+	}
+
+	tflog.SubsystemDebug(logCtx, sqlLogSubsystem, "SQL exec", fields)
+
+	return result, nil
+}
+
+// procedureOutVars reads the @outval and @outmsg set by the last procedure
+// call and logs them with the procedure result.
+func (t *tracingDBTX) procedureOutVars(ctx context.Context) (sql.NullInt64, sql.NullString, error) {
+	var outval sql.NullInt64
+	var outmsg sql.NullString
+
+	err := t.conn.QueryRowContext(ctx, readProcedureOutVars).Scan(&outval, &outmsg)
+	if err != nil {
+		tflog.SubsystemError(t.logContext(ctx), sqlLogSubsystem, "SQL procedure result failed", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return outval, outmsg, err
+	}
+
+	tflog.SubsystemDebug(t.logContext(ctx), sqlLogSubsystem, "SQL procedure result", map[string]interface{}{
+		"outval": outval.Int64,
+		"outmsg": outmsg.String,
+	})
+
+	return outval, outmsg, nil
+}
+
+func (t *tracingDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	tflog.SubsystemTrace(t.logContext(ctx), sqlLogSubsystem, "SQL prepare", queryLogFields(query, nil, nil))
+
+	return t.conn.PrepareContext(ctx, query)
+}
+
+func (t *tracingDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	logCtx := t.logContext(ctx)
+	fields := queryLogFields(query, args, t.secrets)
+
+	start := time.Now()
+	rows, err := t.conn.QueryContext(ctx, query, args...)
+	fields["duration_ms"] = time.Since(start).Milliseconds()
+
+	if err != nil {
+		fields["error"] = err.Error()
+		tflog.SubsystemError(logCtx, sqlLogSubsystem, "SQL query failed", fields)
+		return rows, err
+	}
+
+	tflog.SubsystemDebug(logCtx, sqlLogSubsystem, "SQL query", fields)
+
+	return rows, nil
+}
+
+func (t *tracingDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	logCtx := t.logContext(ctx)
+	fields := queryLogFields(query, args, t.secrets)
+
+	start := time.Now()
+	row := t.conn.QueryRowContext(ctx, query, args...)
+	fields["duration_ms"] = time.Since(start).Milliseconds()
+
+	// sql.ErrNoRows is how sqlc reports missing rows, it isn't a failure
+	if err := row.Err(); err != nil {
+		fields["error"] = err.Error()
+		tflog.SubsystemError(logCtx, sqlLogSubsystem, "SQL query failed", fields)
+		return row
+	}
+
+	tflog.SubsystemDebug(logCtx, sqlLogSubsystem, "SQL query", fields)
+
+	return row
+}
+
+// queryLogFields returns the log fields describing a query, using the sqlc
+// query name when there is one. tflog only masks top-level string fields so
+// the secrets are masked in the parameters here.
+func queryLogFields(query string, args []interface{}, secrets []string) map[string]interface{} {
+	name := "query"
+	if m := sqlcQueryName.FindStringSubmatch(query); m != nil {
+		name = m[1]
+	}
+
+	return map[string]interface{}{
+		"query_name": name,
+		"query":      strings.Join(strings.Fields(query), " "),
+		"args":       sanitizeArgs(args, secrets),
+	}
+}
+
+func sanitizeArgs(args []interface{}, secrets []string) []interface{} {
+	sanitized := make([]interface{}, 0, len(args))
+
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case sql.NullString:
+			if !v.Valid {
+				sanitized = append(sanitized, nil)
+				continue
+			}
+			sanitized = append(sanitized, truncateArg(maskSecrets(v.String, secrets)))
+		case string:
+			sanitized = append(sanitized, truncateArg(maskSecrets(v, secrets)))
+		default:
+			sanitized = append(sanitized, v)
+		}
+	}
+
+	return sanitized
+}
+
+func maskSecrets(s string, secrets []string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, maskedSecret)
+		}
+	}
+
+	return s
+}
+
+// truncateArg cuts s after maxLoggedArgLength bytes, on a rune boundary so
+// that the logs stay valid UTF-8.
+func truncateArg(s string) string {
+	if len(s) <= maxLoggedArgLength {
+		return s
+	}
+
+	end := maxLoggedArgLength
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+
+	return s[:end] + "..."
+}
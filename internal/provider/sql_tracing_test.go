@@ -0,0 +1,135 @@
+// Copyright (c) Mario Finelli
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"terraform-provider-cloudsql-auditlog/db"
+	"terraform-provider-cloudsql-auditlog/internal/cloudsqlfake"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+// testTracingDBTX returns a traced connection to a fake instance logging to
+// the returned output.
+func testTracingDBTX(t *testing.T, secrets ...string) (context.Context, *tracingDBTX, *bytes.Buffer) {
+	t.Helper()
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	conn, err := cloudsqlfake.New().DB().Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return ctx, &tracingDBTX{conn: conn, secrets: secrets}, &output
+}
+
+func TestTracingDBTXExec(t *testing.T) {
+	ctx, traced, output := testTracingDBTX(t, "hunter2")
+
+	err := db.New(traced).CreateAuditRule(ctx, db.CreateAuditRuleParams{
+		Username:  "hunter2@%",
+		Dbname:    "*",
+		Object:    "*",
+		Operation: "ddl",
+		OpResult:  "B",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if outval, _, err := traced.procedureOutVars(ctx); err != nil || outval.Int64 != 0 {
+		t.Fatalf("expected the procedure to succeed, got %v: %v", outval, err)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected the exec and the procedure result entries, got %v", entries)
+	}
+
+	entry := entries[0]
+	if entry["@message"] != "SQL exec" || entry["@module"] != "provider.sql" {
+		t.Errorf("unexpected log entry: %v", entry)
+	}
+	if entry["query_name"] != "CreateAuditRule" {
+		t.Errorf("expected the sqlc query name, got %v", entry["query_name"])
+	}
+	if entry["rows_affected"] != float64(0) {
+		t.Errorf("expected the rows affected, got %v", entry)
+	}
+	if args := entry["args"].([]interface{}); args[0] != "***@%" {
+		t.Errorf("expected the secret to be masked, got %v", args)
+	}
+
+	result := entries[1]
+	if result["@message"] != "SQL procedure result" || result["outval"] != float64(0) || result["outmsg"] != "OK" {
+		t.Errorf("expected the procedure out variables, got %v", result)
+	}
+}
+
+func TestTracingDBTXQueryError(t *testing.T) {
+	ctx, traced, output := testTracingDBTX(t)
+
+	if _, err := traced.QueryContext(ctx, "SELECT\n  secret_column\nFROM nowhere"); err == nil {
+		t.Fatal("expected an unsupported query to fail")
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0]["@message"] != "SQL query failed" || entries[0]["@level"] != "error" {
+		t.Fatalf("expected a single error entry, got %v", entries)
+	}
+	if entries[0]["query_name"] != "query" || entries[0]["query"] != "SELECT secret_column FROM nowhere" {
+		t.Errorf("expected the unnamed query on a single line, got %v", entries[0])
+	}
+}
+
+func TestTruncateArg(t *testing.T) {
+	// the multi-byte rune straddles the length limit
+	s := strings.Repeat("x", maxLoggedArgLength-1) + "é" + "tail"
+
+	got := truncateArg(s)
+	if !utf8.ValidString(got) {
+		t.Fatalf("expected valid UTF-8, got %q", got)
+	}
+	if want := strings.Repeat("x", maxLoggedArgLength-1) + "..."; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestSanitizeArgs(t *testing.T) {
+	long := strings.Repeat("x", maxLoggedArgLength+1)
+
+	args := sanitizeArgs([]interface{}{
+		"app",
+		sql.NullString{String: "description of hunter2", Valid: true},
+		sql.NullString{},
+		long,
+		int64(7),
+	}, []string{"hunter2"})
+
+	want := []interface{}{"app", "description of ***", nil, long[:maxLoggedArgLength] + "...", int64(7)}
+	if len(args) != len(want) {
+		t.Fatalf("expected %v, got %v", want, args)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("argument %d: expected %v, got %v", i, want[i], args[i])
+		}
+	}
+}
@@ -6,7 +6,11 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
+	"io"
 	"log"
+	"os"
+	"path/filepath"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"terraform-provider-cloudsql-auditlog/internal/provider"
@@ -22,6 +26,13 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "generate" {
+		if err := generate(os.Args[2:]); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
 	var debug bool
 
 	flag.BoolVar(&debug, "debug", false, "set to true to run the provider with support for debuggers like delve")
@@ -41,3 +52,71 @@ func main() {
 		log.Fatal(err.Error())
 	}
 }
+
+// generate writes the terraform configuration and import blocks for the
+// audit rules that already exist on an instance.
+func generate(args []string) error {
+	var opts provider.GenerateOptions
+	var output string
+
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s generate [options]\n\n", os.Args[0])
+		fmt.Fprintf(flags.Output(), "Writes a resource and an import block for every audit rule of an instance.\n")
+		fmt.Fprintf(flags.Output(), "The password is read from CLOUDSQL_AUDITLOG_PASSWORD, -password-file or -password-command.\n\n")
+		flags.PrintDefaults()
+	}
+
+	flags.StringVar(&opts.Engine, "engine", "mysql", "database engine of the instance")
+	flags.StringVar(&opts.Endpoint, "endpoint", "", "instance endpoint as host:port")
+	flags.StringVar(&opts.Username, "username", "", "user to connect as")
+	flags.StringVar(&opts.PasswordFile, "password-file", "", "file to read the password from")
+	flags.Func("password-command", "command printing the password, repeat the flag for each argument", func(arg string) error {
+		opts.PasswordCommand = append(opts.PasswordCommand, arg)
+		return nil
+	})
+	flags.DurationVar(&opts.PasswordCommandTimeout, "password-command-timeout", 0, "timeout of the password command (default 30s)")
+	flags.StringVar(&opts.Tls, "tls", "", "mysql driver tls setting, e.g. true or skip-verify")
+	flags.StringVar(&opts.Instance, "instance", "", "provider instance name to set on the generated resources")
+	flags.StringVar(&output, "output", "", "file to write the configuration to (default stdout)")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	opts.Password = os.Getenv("CLOUDSQL_AUDITLOG_PASSWORD")
+
+	if output == "" {
+		return provider.Generate(context.Background(), opts, os.Stdout)
+	}
+
+	return writeFile(output, func(w io.Writer) error {
+		return provider.Generate(context.Background(), opts, w)
+	})
+}
+
+// writeFile writes to a temporary file next to name and only replaces name
+// once write succeeded, so a failed run doesn't leave a truncated file.
+func writeFile(name string, write func(io.Writer) error) error {
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+
+	err = f.Chmod(0o644)
+	if err == nil {
+		err = write(f)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), name)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return nil
+}
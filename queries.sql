@@ -2,7 +2,7 @@
 -- SPDX-License-Identifier: MPL-2.0
 
 -- name: GetAllAuditRules :many
-SELECT * FROM audit_log_rules;
+SELECT * FROM audit_log_rules ORDER BY id;
 
 -- name: CreateAuditRule :exec
 CALL mysql.cloudsql_create_audit_rule(sqlc.arg(username), sqlc.arg(dbname), sqlc.arg(object), sqlc.arg(operation), sqlc.arg(op_result), 1, @outval, @outmsg);
@@ -23,3 +23,23 @@ CALL mysql.cloudsql_update_audit_rule(sqlc.arg(id), sqlc.arg(username), sqlc.arg
 
 -- name: DeleteAuditRuleByID :exec
 CALL mysql.cloudsql_delete_audit_rule(sqlc.arg(id), 1, @outval, @outmsg);
+
+-- name: ReloadAuditRules :exec
+CALL mysql.cloudsql_reload_audit_rule(1, @outval, @outmsg);
+
+-- name: GetAllAuditRuleMetadata :many
+SELECT * FROM tf_audit_rule_metadata;
+
+-- name: ReadAuditRuleMetadataByRuleID :one
+SELECT * FROM tf_audit_rule_metadata WHERE rule_id = ?;
+
+-- name: UpsertAuditRuleMetadata :exec
+INSERT INTO tf_audit_rule_metadata (rule_id, description, owner, ticket)
+VALUES (?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+	description = VALUES(description),
+	owner = VALUES(owner),
+	ticket = VALUES(ticket);
+
+-- name: DeleteAuditRuleMetadataByRuleID :exec
+DELETE FROM tf_audit_rule_metadata WHERE rule_id = ?;
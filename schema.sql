@@ -9,4 +9,12 @@ CREATE TABLE `audit_log_rules` (
 	  `operation` varchar(2048) COLLATE utf8mb4_bin NOT NULL,
 	  `op_result` char(1) COLLATE utf8mb4_bin NOT NULL,
 	  PRIMARY KEY (`id`)
-) ENGINE=InnoDB AUTO_INCREMENT=8 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin
+) ENGINE=InnoDB AUTO_INCREMENT=8 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE `tf_audit_rule_metadata` (
+	  `rule_id` bigint NOT NULL,
+	  `description` varchar(2048) COLLATE utf8mb4_bin DEFAULT NULL,
+	  `owner` varchar(255) COLLATE utf8mb4_bin DEFAULT NULL,
+	  `ticket` varchar(255) COLLATE utf8mb4_bin DEFAULT NULL,
+	  PRIMARY KEY (`rule_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;